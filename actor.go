@@ -0,0 +1,91 @@
+package baconator
+
+import (
+	"sort"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// DefaultCenter is the cast member that /actor reports Bacon numbers relative to unless the server is
+// given another one
+const DefaultCenter = "Kevin Bacon"
+
+type filmographyEntry struct {
+	Title    string `json:"title"`
+	Year     int    `json:"year"`
+	CastSize int    `json:"cast_size"`
+}
+
+type actorResult struct {
+	Resolved *nameMatch `json:"resolved"`
+
+	// Degree is the number of movies the actor is in
+	Degree      int     `json:"degree"`
+	Component   int     `json:"component"`
+	PageRank    float64 `json:"pagerank,omitempty"`
+	Betweenness float64 `json:"betweenness,omitempty"`
+
+	// BaconNumber is the number of movies between the actor and Center. It is nil when they aren't
+	// linked.
+	Center      string `json:"center,omitempty"`
+	BaconNumber *int   `json:"bacon_number"`
+
+	Filmography []*filmographyEntry `json:"filmography"`
+}
+
+// actor builds node's profile. When center isn't nil, the profile includes node's Bacon number
+// relative to center.
+func (b *Baconator) actor(node graph.Node, center *nameMatch) *actorResult {
+	info := b.NodeInfo[node]
+	result := actorResult{
+		Degree:      b.degree(node),
+		Component:   b.Graph.Components().Component(node),
+		PageRank:    info.PageRank,
+		Betweenness: info.Betweenness,
+		Filmography: []*filmographyEntry{},
+	}
+	for _, film := range b.Graph.NodeNeighbors(node) {
+		entry := filmographyEntry{
+			Title:    b.NodeInfo[film].Name,
+			CastSize: b.degree(film),
+		}
+		if m := b.Movies[entry.Title]; m != nil {
+			entry.Year = m.Year
+		}
+		result.Filmography = append(result.Filmography, &entry)
+	}
+	sort.Slice(result.Filmography, func(i, j int) bool {
+		if result.Filmography[i].Year != result.Filmography[j].Year {
+			return result.Filmography[i].Year < result.Filmography[j].Year
+		}
+		return result.Filmography[i].Title < result.Filmography[j].Title
+	})
+	if center != nil {
+		result.Center = center.Name
+		if hops, ok := b.baconNumber(node, center.Node); ok {
+			result.BaconNumber = &hops
+		}
+	}
+	return &result
+}
+
+// baconNumber returns the number of movies between a and c and whether they are linked within
+// defaultMaxHops
+func (b *Baconator) baconNumber(a, c graph.Node) (int, bool) {
+	opts := &linkOptions{}
+	if b.Landmarks != nil {
+		if distance, ok := b.Landmarks.Distance(a, c); ok {
+			return distance / 2, distance < opts.maxPathLength()
+		}
+	}
+	pathOpts, err := b.pathOptions(opts, nil, nil)
+	if err != nil {
+		return 0, false
+	}
+	var path []graph.Node
+	outcome := b.Graph.FindPath(&path, opts.maxPathLength(), a, c, nil, pathOpts...)
+	if outcome != graph.PathFound {
+		return 0, false
+	}
+	return len(path) / 2, true
+}
@@ -9,8 +9,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/willabides/baconator/internal/graph"
 )
@@ -22,6 +24,17 @@ const (
 	movieNode
 )
 
+func (t nodeType) String() string {
+	switch t {
+	case movieNode:
+		return "movie"
+	case castNode:
+		return "cast"
+	default:
+		return "unknown"
+	}
+}
+
 type movie struct {
 	Year  int      `json:"year"`
 	Title string   `json:"title"`
@@ -32,6 +45,12 @@ type nodeInfo struct {
 	Node graph.Node
 	Type nodeType
 	Name string
+
+	// Betweenness is the node's betweenness centrality. It is zero until scores are computed or loaded.
+	Betweenness float64
+
+	// PageRank is the node's share of the graph's PageRank. It is zero until scores are computed or loaded.
+	PageRank float64
 }
 
 // Baconator is kind of a big deal around here
@@ -41,6 +60,35 @@ type Baconator struct {
 	NodeInfo   []nodeInfo
 	Graph      *graph.Graph
 	Movies     map[string]*movie
+
+	// Landmarks bounds the distance between nodes so that hopeless link searches end early and some
+	// distances are known without searching. It is nil unless set by BuildLandmarks or LoadLandmarks.
+	Landmarks *graph.Landmarks
+
+	// indexOnce builds the lookup structures below, which aren't serialized with b. A gob decoded
+	// Baconator builds them the first time they're needed.
+	indexOnce sync.Once
+
+	names    *nameIndex
+	prefixes *searchIndex
+
+	// years holds each movie node's release year. It is zero for cast nodes and unknown years.
+	years []int16
+
+	// oldestYear and newestYear are the earliest and latest known years in years
+	oldestYear, newestYear int16
+
+	// neighborOrders holds the precomputed neighbor order for each registered path strategy
+	neighborOrders map[string]*graph.NeighborOrder
+
+	// componentCast holds the number of cast members in each of the graph's connected components
+	componentCast []int
+
+	// projection links cast members who share a movie. It is nil unless BuildProjection was called.
+	projection *graph.Projection
+
+	// cuts measures how many cast members each node is the only link to
+	cuts *graph.Cuts
 }
 
 // LoadFromDatafile loads b with data in filename
@@ -53,8 +101,7 @@ func (b *Baconator) LoadFromDatafile(filename string) error {
 	if err != nil {
 		return err
 	}
-	bac := buildBaconator(movies)
-	*b = *bac
+	b.build(movies)
 	return nil
 }
 
@@ -141,9 +188,16 @@ func parseCastName(nm string) string {
 }
 
 func buildBaconator(movies map[string]*movie) *Baconator {
+	var b Baconator
+	b.build(movies)
+	return &b
+}
+
+// build replaces everything in b with the graph of movies
+func (b *Baconator) build(movies map[string]*movie) {
 	movieCast, castMovies := buildNeighbors(movies)
 
-	b := Baconator{
+	*b = Baconator{
 		CastNodes:  make(map[string]graph.Node, len(movieCast)),
 		MovieNodes: make(map[string]graph.Node, len(movieCast)),
 		NodeInfo:   make([]nodeInfo, 0, len(movieCast)+len(castMovies)),
@@ -179,7 +233,50 @@ func buildBaconator(movies map[string]*movie) *Baconator {
 		}
 	}
 	b.Graph = b.buildGraph(movieCast, castMovies)
-	return &b
+	b.loadIndexes()
+}
+
+// loadIndexes builds the lookup structures that aren't serialized with b unless they are already built.
+// Everything that reads them calls it first.
+func (b *Baconator) loadIndexes() {
+	b.indexOnce.Do(b.buildIndexes)
+}
+
+// buildIndexes builds the lookup structures that aren't serialized with b. Use loadIndexes instead.
+func (b *Baconator) buildIndexes() {
+	b.names = b.buildNameIndex()
+	b.prefixes = b.buildSearchIndex()
+	b.years = b.buildYears()
+	b.oldestYear, b.newestYear = yearRange(b.years)
+	b.neighborOrders = b.buildNeighborOrders()
+	b.componentCast = b.buildComponentCast()
+	b.cuts = b.Graph.FindCuts(b.castWeight)
+}
+
+func (b *Baconator) buildYears() []int16 {
+	years := make([]int16, len(b.NodeInfo))
+	for title, node := range b.MovieNodes {
+		if film := b.Movies[title]; film != nil {
+			years[node] = int16(film.Year)
+		}
+	}
+	return years
+}
+
+// yearRange returns the earliest and latest years in years, skipping unknown years
+func yearRange(years []int16) (oldest, newest int16) {
+	for _, year := range years {
+		if year == 0 {
+			continue
+		}
+		if oldest == 0 || year < oldest {
+			oldest = year
+		}
+		if year > newest {
+			newest = year
+		}
+	}
+	return oldest, newest
 }
 
 func (b *Baconator) buildGraph(movieCast, castMovies stringNeighbors) *graph.Graph {
@@ -240,74 +337,39 @@ func buildNeighbors(movies map[string]*movie) (movieCast, castMovies stringNeigh
 	return movieCast, castMovies
 }
 
-type linksResult struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-}
-
-func (b *Baconator) links(src, dest string) ([]linksResult, error) {
-	srcNode, ok := b.CastNodes[src]
-	if !ok {
-		return nil, fmt.Errorf("unknown cast member: %q", src)
-	}
-	destNode, ok := b.CastNodes[dest]
-	if !ok {
-		return nil, fmt.Errorf("unknown cast member: %q", dest)
-	}
-	var path []graph.Node
-	var pri graph.PriorityFunc = func(node graph.Node) int64 {
-		info := b.NodeInfo[node]
-		if info.Type != movieNode {
-			return 0
-		}
-		year := 10000
-		film := b.Movies[info.Name]
-		if film != nil && film.Year > 0 {
-			year = film.Year
-		}
-		return int64(year * -1)
-	}
-	b.Graph.FindPath(&path, 99, srcNode, destNode, pri)
-	res := make([]linksResult, len(path))
-	for i, node := range path {
-		info := b.NodeInfo[node]
-		res[i].Name = info.Name
-		switch info.Type {
-		case movieNode:
-			res[i].Type = "movie"
-		case castNode:
-			res[i].Type = "cast"
-		default:
-			res[i].Type = "unknown"
-		}
-	}
-	return res, nil
-}
-
 type centerResult struct {
 	Distance    map[int]int `json:"count_by_distance"`
 	Total       int         `json:"total_linkable"`
 	AvgDistance float64     `json:"average_distance"`
 }
 
+// maxCenterWorkers is the most goroutines one center search runs on so that concurrent /center
+// requests don't each take every CPU
+const maxCenterWorkers = 4
+
+// center searches the whole graph from center
 func (b *Baconator) center(center graph.Node) *centerResult {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > maxCenterWorkers {
+		workers = maxCenterWorkers
+	}
+	g, _ := b.distanceGraph()
+	levels := g.BorrowLevels()
+	defer g.ReturnLevels(levels)
+	g.FindLevelsInto(*levels, center, workers)
+	return b.centerFromLevels(center, *levels)
+}
+
+// centerFromLevels builds the center result for center from the levels found by searching from it.
+// Only the cast members the search reached, including center, count toward the total and the average.
+func (b *Baconator) centerFromLevels(center graph.Node, levels []uint8) *centerResult {
 	result := centerResult{
 		Distance: map[int]int{},
 	}
-	levels := b.Graph.FindLevels(center)
-	var maxLevel int
 	if b.NodeInfo[center].Type != castNode {
 		return nil
 	}
-	for i, level := range levels {
-		if b.NodeInfo[i].Type != castNode {
-			continue
-		}
-		result.Total++
-		if level > maxLevel {
-			maxLevel = level
-		}
-	}
+	_, levelsPerHop := b.distanceGraph()
 	var tot float64
 	for i, level := range levels {
 		if level == 0 {
@@ -316,8 +378,10 @@ func (b *Baconator) center(center graph.Node) *centerResult {
 		if b.NodeInfo[i].Type != castNode {
 			continue
 		}
-		tot += float64(level / 2)
-		result.Distance[level/2]++
+		hops := int(level-1) / levelsPerHop
+		result.Total++
+		tot += float64(hops)
+		result.Distance[hops]++
 	}
 	result.AvgDistance = tot / float64(result.Total)
 	return &result
@@ -1,11 +1,18 @@
 package baconator
 
 import (
+	"bytes"
 	"encoding/gob"
+	"errors"
 	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,9 +20,697 @@ import (
 
 func TestBaconator_Links(t *testing.T) {
 	b := newTestBaconator(t)
-	got, err := b.links("James Dean", "Ruth Buzzi")
+	got, err := b.links("James Dean", "Ruth Buzzi", nil)
 	require.NoError(t, err)
-	require.Greater(t, len(got), 0)
+	require.Greater(t, len(got.Path), 0)
+}
+
+func TestBaconator_links_noPath(t *testing.T) {
+	b := newFixtureBaconator(t)
+	got, err := b.links("Elizabeth Perkins", "Harrison Ford", nil)
+	require.NoError(t, err)
+	require.Len(t, got.Path, 11)
+
+	_, err = b.links("Elizabeth Perkins", "Harrison Ford", &linkOptions{maxHops: 4})
+	require.EqualError(t, err, `no link between "Elizabeth Perkins" and "Harrison Ford" within 4 hops`)
+
+	_, err = b.links("Elizabeth Perkins", "Other Loner", nil)
+	require.EqualError(t, err, `"Elizabeth Perkins" and "Other Loner" are not linked`)
+}
+
+func TestBaconator_landmarks(t *testing.T) {
+	b := newFixtureBaconator(t)
+	require.Nil(t, b.Landmarks)
+	b.BuildLandmarks()
+	require.NotNil(t, b.Landmarks)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(b))
+	var decoded Baconator
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	require.Equal(t, b.Landmarks, decoded.Landmarks)
+
+	lower, upper, connected := decoded.Landmarks.DistanceBounds(b.CastNodes["Elizabeth Perkins"], b.CastNodes["Harrison Ford"])
+	require.True(t, connected)
+	require.LessOrEqual(t, lower, 10)
+	require.GreaterOrEqual(t, upper, 10)
+	_, err := decoded.links("Elizabeth Perkins", "Other Loner", nil)
+	require.EqualError(t, err, `"Elizabeth Perkins" and "Other Loner" are not linked`)
+	got, err := decoded.links("Elizabeth Perkins", "Harrison Ford", nil)
+	require.NoError(t, err)
+	require.Len(t, got.Path, 11)
+	number, ok := decoded.baconNumber(b.CastNodes["Harrison Ford"], b.CastNodes["Kevin Bacon"])
+	require.True(t, ok)
+	require.Equal(t, 3, number)
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	filename := filepath.Join(dir, "landmarks.gob")
+	require.NoError(t, b.WriteLandmarks(filename))
+	loaded := newFixtureBaconator(t)
+	require.NoError(t, loaded.LoadLandmarks(filename))
+	require.Equal(t, b.Landmarks, loaded.Landmarks)
+
+	other := newFixtureBaconator(t, edgeOfTomorrow())
+	require.EqualError(t, other.LoadLandmarks(filename), "landmarks in "+filename+" were built from different data")
+	require.Nil(t, other.Landmarks)
+	require.EqualError(t, other.WriteLandmarks(filename), "no landmarks to write")
+}
+
+func BenchmarkBaconator_links(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	movies := make(map[string]*movie, 20000)
+	for i := 0; i < 20000; i++ {
+		film := movie{
+			Title: "Movie " + strconv.Itoa(i),
+			Year:  1920 + rnd.Intn(100),
+			Cast:  make([]string, 2+rnd.Intn(8)),
+		}
+		for j := range film.Cast {
+			film.Cast[j] = "Actor " + strconv.Itoa(int(50000*math.Pow(rnd.Float64(), 3)))
+		}
+		movies[film.Title] = &film
+	}
+	plain := buildBaconator(movies)
+	names := make([]string, 0, len(plain.CastNodes))
+	for name := range plain.CastNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	withLandmarks := buildBaconator(movies)
+	withLandmarks.BuildLandmarks()
+	for _, bb := range []struct {
+		name      string
+		baconator *Baconator
+	}{
+		{name: "none", baconator: plain},
+		{name: "landmarks", baconator: withLandmarks},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				src := names[i%len(names)]
+				dest := names[i*7919%len(names)]
+				_, err := bb.baconator.links(src, dest, nil)
+				var noPath *noPathError
+				if err != nil && !errors.As(err, &noPath) {
+					b.Fatal(err)
+				}
+			}
+			b.ReportAllocs()
+		})
+	}
+}
+
+func TestBaconator_links_k(t *testing.T) {
+	// Tom Hanks and Tom Cruise are linked by two paths of 2 hops, one of 3 hops and one of 4 hops
+	b := newFixtureBaconator(t,
+		edgeOfTomorrow(),
+		&movie{Title: "Twister", Year: 1996, Cast: []string{"[[Bill Paxton]]", "[[Helen Hunt]]"}},
+		&movie{Title: "Cast Away", Year: 2000, Cast: []string{"[[Tom Hanks]]", "[[Helen Hunt]]"}},
+	)
+	names := func(paths [][]linksResult) [][]string {
+		result := [][]string{}
+		for _, path := range paths {
+			var steps []string
+			for _, step := range path {
+				steps = append(steps, step.Name)
+			}
+			result = append(result, steps)
+		}
+		return result
+	}
+	got, err := b.links("Tom Hanks", "Tom Cruise", &linkOptions{k: 3})
+	require.NoError(t, err)
+	require.Equal(t, got.Path, got.Paths[0])
+	require.Equal(t, [][]string{
+		{"Tom Hanks", "Apollo 13 (film)", "Kevin Bacon", "A Few Good Men", "Tom Cruise"},
+		{"Tom Hanks", "Apollo 13 (film)", "Bill Paxton", "Edge of Tomorrow", "Tom Cruise"},
+		{"Tom Hanks", "Cast Away", "Helen Hunt", "Twister", "Bill Paxton", "Edge of Tomorrow", "Tom Cruise"},
+	}, names(got.Paths))
+
+	got, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{k: 2})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"Tom Hanks", "Apollo 13 (film)", "Kevin Bacon", "A Few Good Men", "Tom Cruise"},
+		{"Tom Hanks", "Apollo 13 (film)", "Bill Paxton", "Edge of Tomorrow", "Tom Cruise"},
+	}, names(got.Paths))
+
+	got, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{k: 10})
+	require.NoError(t, err)
+	require.Len(t, got.Paths, 4)
+	require.Equal(t, []string{
+		"Tom Hanks", "Cast Away", "Helen Hunt", "Twister", "Bill Paxton",
+		"Apollo 13 (film)", "Kevin Bacon", "A Few Good Men", "Tom Cruise",
+	}, names(got.Paths)[3])
+
+	got, err = b.links("Tom Hanks", "Tom Cruise", nil)
+	require.NoError(t, err)
+	require.Empty(t, got.Paths)
+}
+
+func TestBaconator_links_exclude(t *testing.T) {
+	b := newFixtureBaconator(t, edgeOfTomorrow())
+	got, err := b.links("Tom Hanks", "Tom Cruise", &linkOptions{excludeCast: []string{"kevin bacon"}})
+	require.NoError(t, err)
+	require.Equal(t, []linksResult{
+		{Name: "Tom Hanks", Type: "cast"},
+		{Name: "Apollo 13 (film)", Type: "movie"},
+		{Name: "Bill Paxton", Type: "cast"},
+		{Name: "Edge of Tomorrow", Type: "movie"},
+		{Name: "Tom Cruise", Type: "cast"},
+	}, got.Path)
+
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{
+		excludeCast:   []string{"Kevin Bacon"},
+		excludeMovies: []string{"Edge of Tomorrow"},
+	})
+	require.EqualError(t, err, `"Tom Hanks" and "Tom Cruise" are not linked`)
+
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{excludeMovies: []string{"Nope"}})
+	require.EqualError(t, err, `unknown movie: "Nope"`)
+
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{excludeCast: []string{"tom hanks"}})
+	require.EqualError(t, err, `"Tom Hanks" is an end of the link and can't be excluded`)
+
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{excludeCast: []string{"Kevn Bacon"}})
+	var castErr *unknownCastError
+	require.True(t, errors.As(err, &castErr))
+	require.Equal(t, &unknownCastError{name: "Kevn Bacon", suggestions: []string{"Kevin Bacon"}}, castErr)
+}
+
+func TestBaconator_links_years(t *testing.T) {
+	b := newFixtureBaconator(t, edgeOfTomorrow())
+	got, err := b.links("Tom Hanks", "Tom Cruise", &linkOptions{toYear: 2000})
+	require.NoError(t, err)
+	require.Equal(t, "A Few Good Men", got.Path[3].Name)
+
+	got, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{fromYear: 1993})
+	require.NoError(t, err)
+	require.Equal(t, "Edge of Tomorrow", got.Path[3].Name)
+
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{fromYear: 1993, toYear: 2000})
+	require.EqualError(t, err, `"Tom Hanks" and "Tom Cruise" are not linked`)
+}
+
+func TestBaconator_links_strategy(t *testing.T) {
+	b := newFixtureBaconator(t, edgeOfTomorrow())
+	for strategy, want := range map[string]string{
+		"":       "A Few Good Men",
+		"oldest": "A Few Good Men",
+		"newest": "Edge of Tomorrow",
+	} {
+		got, err := b.links("Tom Hanks", "Tom Cruise", &linkOptions{strategy: strategy})
+		require.NoError(t, err)
+		require.Equal(t, want, got.Path[3].Name, strategy)
+	}
+	for _, strategy := range pathStrategyNames() {
+		got, err := b.links("Tom Hanks", "Tom Cruise", &linkOptions{strategy: strategy})
+		require.NoError(t, err)
+		require.Len(t, got.Path, 5, strategy)
+	}
+	_, err := b.links("Tom Hanks", "Tom Cruise", &linkOptions{strategy: "random"})
+	require.EqualError(t, err, `unknown strategy "random". valid strategies are: alphabetical, famous_costar, newest, oldest, popular`)
+}
+
+func TestBaconator_links_weighted(t *testing.T) {
+	movies := fixtureMovies()
+	for _, m := range []*movie{
+		{Title: "Duet", Year: 1990, Cast: []string{"[[Harrison Ford]]", "[[Pat Partner]]"}},
+		{Title: "Two Hander", Year: 1991, Cast: []string{"[[Pat Partner]]", "[[Kevin Bacon]]"}},
+	} {
+		movies[m.Title] = m
+	}
+	b := buildBaconator(movies)
+
+	got, err := b.links("Kelly McGillis", "Kevin Bacon", nil)
+	require.NoError(t, err)
+	require.Len(t, got.Path, 5)
+	require.Nil(t, got.Cost)
+
+	got, err = b.links("Kelly McGillis", "Kevin Bacon", &linkOptions{weighted: true})
+	require.NoError(t, err)
+	var names []string
+	for _, step := range got.Path {
+		names = append(names, step.Name)
+	}
+	require.Equal(t, []string{"Kelly McGillis", "Witness (1985 film)", "Harrison Ford", "Duet", "Pat Partner", "Two Hander", "Kevin Bacon"}, names)
+	require.NotNil(t, got.Cost)
+	require.Equal(t, float64(3), *got.Cost)
+
+	got, err = b.links("Kelly McGillis", "Kevin Bacon", &linkOptions{weighted: true, excludeCast: []string{"Pat Partner"}})
+	require.NoError(t, err)
+	require.Len(t, got.Path, 5)
+	require.InDelta(t, 2*math.Log2(3), *got.Cost, 1e-9)
+
+	// Top Gun (1986) and A Few Good Men (1992) are newer than Witness (1985), Duet and Two Hander
+	// together. Vanilla Sky (2001) is the newest movie.
+	got, err = b.links("Kelly McGillis", "Kevin Bacon", &linkOptions{weighted: true, cost: costRecency})
+	require.NoError(t, err)
+	require.Equal(t, "Top Gun", got.Path[1].Name)
+	require.Equal(t, "A Few Good Men", got.Path[3].Name)
+	require.InDelta(t, 2.5+1.9, *got.Cost, 1e-9)
+
+	_, err = b.links("Kelly McGillis", "Kevin Bacon", &linkOptions{weighted: true, cost: "nope"})
+	require.EqualError(t, err, `unknown cost "nope". valid costs are: cast_size, recency, collaboration`)
+
+	_, err = b.links("Kevin Bacon", "Sölo Äctor", &linkOptions{weighted: true})
+	require.EqualError(t, err, `"Kevin Bacon" and "Sölo Äctor" are not linked`)
+}
+
+func TestBaconator_links_collaboration(t *testing.T) {
+	// Tom Hanks and Bill Paxton share two movies, so linking Tom Hanks to Tom Cruise through Bill
+	// Paxton costs 1/2 + 1 and through Kevin Bacon costs 1 + 1.
+	b := newFixtureBaconator(t,
+		edgeOfTomorrow(),
+		&movie{Title: "Apollo 13 Reunion", Year: 2005, Cast: []string{"[[Tom Hanks]]", "[[Bill Paxton]]"}},
+	)
+	opts := &linkOptions{weighted: true, cost: costCollaboration}
+	_, err := b.links("Tom Hanks", "Tom Cruise", opts)
+	require.EqualError(t, err, `cost "collaboration" needs the projected graph`)
+
+	b.BuildProjection()
+	got, err := b.links("Tom Hanks", "Tom Cruise", opts)
+	require.NoError(t, err)
+	var names []string
+	for _, step := range got.Path {
+		names = append(names, step.Name)
+	}
+	require.Equal(t, []string{"Tom Hanks", "Apollo 13 (film)", "Bill Paxton", "Edge of Tomorrow", "Tom Cruise"}, names)
+	require.Equal(t, 1.5, *got.Cost)
+
+	// the strategy picks between the movies a pair shares
+	got, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{weighted: true, cost: costCollaboration, strategy: "newest"})
+	require.NoError(t, err)
+	require.Equal(t, "Apollo 13 Reunion", got.Path[1].Name)
+
+	got, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{weighted: true, cost: costCollaboration, excludeCast: []string{"Bill Paxton"}})
+	require.NoError(t, err)
+	require.Equal(t, "Kevin Bacon", got.Path[2].Name)
+	require.Equal(t, float64(2), *got.Cost)
+
+	_, err = b.links("Tom Hanks", "Sölo Äctor", opts)
+	require.EqualError(t, err, `"Tom Hanks" and "Sölo Äctor" are not linked`)
+
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{weighted: true, cost: costCollaboration, excludeMovies: []string{"Apollo 13 (film)"}})
+	require.EqualError(t, err, "exclude_movie is not supported when cost is collaboration")
+	_, err = b.links("Tom Hanks", "Tom Cruise", &linkOptions{weighted: true, cost: costCollaboration, fromYear: 1990})
+	require.EqualError(t, err, "from_year is not supported when cost is collaboration")
+}
+
+func TestBaconator_Stats(t *testing.T) {
+	b := newFixtureBaconator(t)
+	require.Equal(t, &GraphStats{
+		Cast:                   15,
+		Movies:                 8,
+		Components:             2,
+		LargestComponentCast:   13,
+		LargestComponentMovies: 7,
+	}, b.Stats())
+}
+
+func TestBaconator_farthest(t *testing.T) {
+	b := newFixtureBaconator(t)
+	got := b.farthest(b.CastNodes["Kevin Bacon"], 10)
+	require.Equal(t, &farthestResult{
+		BaconNumber: 3,
+		Total:       1,
+		Cast:        []string{"Harrison Ford"},
+	}, got)
+
+	got = b.farthest(b.CastNodes["Harrison Ford"], 10)
+	require.Equal(t, 5, got.BaconNumber)
+	require.Equal(t, []string{"Elizabeth Perkins"}, got.Cast)
+
+	got = b.farthest(b.CastNodes["Jack Nicholson"], 1)
+	require.Equal(t, 3, got.BaconNumber)
+	require.Equal(t, 2, got.Total)
+	require.Equal(t, []string{"Elizabeth Perkins"}, got.Cast)
+
+	diameter := b.Diameter()
+	require.Equal(t, 5, diameter.BaconNumber)
+	require.ElementsMatch(t, []string{"Harrison Ford", "Elizabeth Perkins"}, []string{diameter.From, diameter.To})
+}
+
+func TestBaconator_RankCenters(t *testing.T) {
+	b := newFixtureBaconator(t)
+	ranking := b.RankCenters(3)
+	require.Equal(t, 13, ranking.ComponentSize)
+	require.Len(t, ranking.Centers, 13)
+	for i, center := range ranking.Centers {
+		require.Equal(t, i+1, center.Rank)
+		require.NotEqual(t, "Other Loner", center.Name)
+		want := b.center(b.CastNodes[center.Name])
+		require.Equal(t, want.AvgDistance, center.AverageDistance)
+		require.Equal(t, ranking.ComponentSize, center.TotalLinkable)
+		if i > 0 {
+			require.GreaterOrEqual(t, center.AverageDistance, ranking.Centers[i-1].AverageDistance)
+		}
+	}
+	require.Equal(t, "Kevin Bacon", ranking.Centers[0].Name)
+	require.Equal(t, ranking, b.RankCenters(1))
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	filename := filepath.Join(dir, "centers.json")
+	require.NoError(t, ranking.WriteFile(filename))
+	loaded, err := LoadCenterRanking(filename)
+	require.NoError(t, err)
+	require.Equal(t, ranking, loaded)
+}
+
+func TestBaconator_ComputeBetweenness(t *testing.T) {
+	b := newFixtureBaconator(t)
+	scores := b.ComputeBetweenness(0, 1, 2)
+	require.Equal(t, 23, scores.Samples)
+	// Kevin Bacon and Tom Cruise each link their half of the largest component to the other half
+	require.InDelta(t, scores.Cast["Tom Cruise"], scores.Cast["Kevin Bacon"], 1e-9)
+	for name, score := range scores.Cast {
+		require.LessOrEqual(t, score, scores.Cast["Kevin Bacon"]+1e-9, name)
+	}
+	require.NotContains(t, scores.Cast, "Harrison Ford")
+	// Lonely Film links one of the 22 * 21 / 2 pairs of other nodes
+	require.InDelta(t, 1.0/231, scores.Movies["Lonely Film"], 1e-9)
+	require.Equal(t, scores.Cast["Kevin Bacon"], b.NodeInfo[b.CastNodes["Kevin Bacon"]].Betweenness)
+	hits := b.search("kevin bacon", &searchOptions{limit: 1})
+	require.Equal(t, scores.Cast["Kevin Bacon"], hits.Results[0].Betweenness)
+
+	sampled := b.ComputeBetweenness(10, 1, 0)
+	require.Equal(t, 10, sampled.Samples)
+	require.Equal(t, sampled, b.ComputeBetweenness(10, 1, 3))
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	filename := filepath.Join(dir, "betweenness.json")
+	require.NoError(t, scores.WriteFile(filename))
+	loaded, err := LoadBetweennessScores(filename)
+	require.NoError(t, err)
+	require.Equal(t, scores, loaded)
+	other := newFixtureBaconator(t)
+	other.SetBetweenness(loaded)
+	require.Equal(t, scores.Cast["Kevin Bacon"], other.NodeInfo[other.CastNodes["Kevin Bacon"]].Betweenness)
+	require.Equal(t, scores.Movies["Top Gun"], other.NodeInfo[other.MovieNodes["Top Gun"]].Betweenness)
+}
+
+func TestBaconator_related(t *testing.T) {
+	b := newFixtureBaconator(t)
+	got := b.related(b.CastNodes["Harrison Ford"], 3, nil)
+	require.Len(t, got.Related, 3)
+	require.Equal(t, "Kelly McGillis", got.Related[0].Name)
+	require.Equal(t, "Tom Cruise", got.Related[1].Name)
+	require.Greater(t, got.Related[0].Score, got.Related[1].Score)
+
+	got = b.related(b.CastNodes["Sölo Äctor"], 10, nil)
+	require.Len(t, got.Related, 1)
+	require.Equal(t, "Other Loner", got.Related[0].Name)
+}
+
+func TestBaconator_ComputePageRank(t *testing.T) {
+	b := newFixtureBaconator(t)
+	require.Zero(t, b.NodeInfo[b.CastNodes["Kevin Bacon"]].PageRank)
+	scores := b.ComputePageRank(0, 0)
+	require.Greater(t, scores.Iterations, 0)
+	best := b.NodeInfo[b.CastNodes["Kevin Bacon"]].PageRank
+	require.Equal(t, scores.Cast["Kevin Bacon"], best)
+	require.InDelta(t, best, b.NodeInfo[b.CastNodes["Tom Cruise"]].PageRank, 1e-9)
+	for name, node := range b.CastNodes {
+		require.LessOrEqual(t, b.NodeInfo[node].PageRank, best+1e-9, name)
+	}
+	var total float64
+	for _, score := range scores.Cast {
+		total += score
+	}
+	for _, score := range scores.Movies {
+		total += score
+	}
+	require.InDelta(t, 1, total, 1e-6)
+
+	// less damping spreads the scores more evenly
+	flat := newFixtureBaconator(t).ComputePageRank(0.5, 0)
+	require.Less(t, flat.Cast["Kevin Bacon"], best)
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	filename := filepath.Join(dir, "pagerank.json")
+	require.NoError(t, scores.WriteFile(filename))
+	loaded, err := LoadPageRankScores(filename)
+	require.NoError(t, err)
+	require.Equal(t, scores, loaded)
+	other := newFixtureBaconator(t)
+	other.SetPageRank(loaded)
+	require.Equal(t, b.NodeInfo, other.NodeInfo)
+}
+
+func TestBaconator_BuildProjection(t *testing.T) {
+	b := newFixtureBaconator(t)
+	centers := map[string]*centerResult{}
+	for name, node := range b.CastNodes {
+		centers[name] = b.center(node)
+	}
+	farthest := b.farthest(b.CastNodes["Kevin Bacon"], 10)
+	diameter := b.Diameter()
+	ranking := b.RankCenters(2)
+
+	b.BuildProjection()
+	g, levelsPerHop := b.distanceGraph()
+	require.Equal(t, 1, levelsPerHop)
+	require.Same(t, b.projection.Graph, g)
+	require.Equal(t, 1, b.projection.Weight(b.CastNodes["Kevin Bacon"], b.CastNodes["Tom Cruise"]))
+	require.Equal(t, 0, b.projection.Weight(b.CastNodes["Kevin Bacon"], b.CastNodes["Harrison Ford"]))
+	require.Empty(t, b.projection.NodeNeighbors(b.MovieNodes["Top Gun"]))
+
+	projected := map[string]*centerResult{}
+	for name, node := range b.CastNodes {
+		projected[name] = b.center(node)
+	}
+	require.Equal(t, centers, projected)
+	require.Equal(t, farthest, b.farthest(b.CastNodes["Kevin Bacon"], 10))
+	require.Equal(t, diameter, b.Diameter())
+	require.Equal(t, ranking, b.RankCenters(2))
+}
+
+func TestBaconator_costars(t *testing.T) {
+	movies := fixtureMovies()
+	movies["Top Gun: Maverick"] = &movie{Title: "Top Gun: Maverick", Year: 2022, Cast: []string{"[[Tom Cruise]]", "[[Val Kilmer]]"}}
+	b := buildBaconator(movies)
+
+	got := b.costars(b.CastNodes["Tom Cruise"], 2, 0)
+	require.Equal(t, 6, got.Total)
+	require.Equal(t, []*costar{
+		{
+			Name:           "Val Kilmer",
+			Collaborations: 2,
+			Movies: []*costarMovie{
+				{Title: "Top Gun", Year: 1986},
+				{Title: "Top Gun: Maverick", Year: 2022},
+			},
+		},
+		{
+			Name:           "Cameron Diaz",
+			Collaborations: 1,
+			Movies:         []*costarMovie{{Title: "Vanilla Sky", Year: 2001}},
+		},
+	}, got.Costars)
+
+	got = b.costars(b.CastNodes["Tom Cruise"], 100, 5)
+	require.Len(t, got.Costars, 1)
+	require.Equal(t, "Penélope Cruz", got.Costars[0].Name)
+
+	got = b.costars(b.CastNodes["Tom Cruise"], 100, 6)
+	require.Equal(t, 6, got.Total)
+	require.Empty(t, got.Costars)
+}
+
+func TestBaconator_actor(t *testing.T) {
+	b := newFixtureBaconator(t)
+	center, err := b.resolveCast("Kevin Bacon")
+	require.NoError(t, err)
+	got := b.actor(b.CastNodes["Tom Cruise"], center)
+	one := 1
+	require.Equal(t, &actorResult{
+		Degree:      3,
+		Component:   0,
+		PageRank:    b.NodeInfo[b.CastNodes["Tom Cruise"]].PageRank,
+		Center:      "Kevin Bacon",
+		BaconNumber: &one,
+		Filmography: []*filmographyEntry{
+			{Title: "Top Gun", Year: 1986, CastSize: 3},
+			{Title: "A Few Good Men", Year: 1992, CastSize: 3},
+			{Title: "Vanilla Sky", Year: 2001, CastSize: 3},
+		},
+	}, got)
+
+	got = b.actor(b.CastNodes["Kevin Bacon"], center)
+	require.Equal(t, 0, *got.BaconNumber)
+
+	got = b.actor(b.CastNodes["Sölo Äctor"], center)
+	require.Equal(t, 1, got.Component)
+	require.Equal(t, "Kevin Bacon", got.Center)
+	require.Nil(t, got.BaconNumber)
+
+	got = b.actor(b.CastNodes["Harrison Ford"], nil)
+	require.Empty(t, got.Center)
+	require.Nil(t, got.BaconNumber)
+	require.Len(t, got.Filmography, 1)
+}
+
+func TestBaconator_movie(t *testing.T) {
+	b := newFixtureBaconator(t)
+	node := b.MovieNodes["Top Gun"]
+	require.Equal(t, &movieResult{
+		Year: 1986,
+		Cast: []*movieCastMember{
+			{Name: "Kelly McGillis", Degree: 2},
+			{Name: "Tom Cruise", Degree: 3},
+			{Name: "Val Kilmer", Degree: 1},
+		},
+		Component:    0,
+		PageRank:     b.NodeInfo[node].PageRank,
+		Bridge:       true,
+		StrandedCast: 3,
+	}, b.movie(node))
+
+	for title, stranded := range map[string]int{
+		"Footloose":        2,
+		"Apollo 13 (film)": 3,
+		"A Few Good Men":   7,
+		"Lonely Film":      1,
+	} {
+		got := b.movie(b.MovieNodes[title])
+		require.True(t, got.Bridge, title)
+		require.Equal(t, stranded, got.StrandedCast, title)
+	}
+}
+
+func TestBaconator_resolveMovie(t *testing.T) {
+	b := newFixtureBaconator(t)
+	got, err := b.resolveMovie("Top Gun")
+	require.NoError(t, err)
+	require.Equal(t, "Top Gun", got.Name)
+	require.Equal(t, matchExact, got.Method)
+
+	got, err = b.resolveMovie("apollo 13")
+	require.NoError(t, err)
+	require.Equal(t, "Apollo 13 (film)", got.Name)
+	require.Equal(t, matchNormalized, got.Method)
+
+	_, err = b.resolveMovie("apollo")
+	require.EqualError(t, err, `unknown movie: "apollo"`)
+	_, err = b.resolveMovie("Kevin Bacon")
+	require.EqualError(t, err, `unknown movie: "Kevin Bacon"`)
+}
+
+func TestBaconator_resolveCast(t *testing.T) {
+	b := newFixtureBaconator(t)
+	for _, td := range []struct {
+		query, name, method string
+		distance            int
+	}{
+		{query: "Kevin Bacon", name: "Kevin Bacon", method: matchExact},
+		{query: "kevin bacon", name: "Kevin Bacon", method: matchNormalized},
+		{query: "Kevin Bacon (actor)", name: "Kevin Bacon", method: matchNormalized},
+		{query: "penelope  cruz", name: "Penélope Cruz", method: matchNormalized},
+		{query: "Kevn Bacon", name: "Kevin Bacon", method: matchFuzzy, distance: 1},
+		{query: "Tom Hanx", name: "Tom Hanks", method: matchFuzzy, distance: 2},
+	} {
+		got, err := b.resolveCast(td.query)
+		require.NoError(t, err, td.query)
+		require.Equal(t, td.name, got.Name, td.query)
+		require.Equal(t, td.method, got.Method, td.query)
+		require.Equal(t, td.distance, got.Distance, td.query)
+		require.Equal(t, b.CastNodes[td.name], got.Node, td.query)
+	}
+	_, err := b.resolveCast("Nobody At All")
+	require.EqualError(t, err, `unknown cast member: "Nobody At All"`)
+
+	_, err = b.resolveCast("Tom Crooz")
+	var castErr *unknownCastError
+	require.True(t, errors.As(err, &castErr))
+	require.Equal(t, []string{"Tom Cruise"}, castErr.suggestions)
+}
+
+func Test_nameIndex_closest(t *testing.T) {
+	b := newFixtureBaconator(t)
+	b.loadIndexes()
+	var keys []string
+	for key := range b.names.normalized {
+		keys = append(keys, key)
+	}
+	for _, query := range []string{"tom cruz", "kevn bacon", "tom", "harrison fordd", "zzzzzzzzzzzz"} {
+		for _, n := range []int{1, 3, maxSuggestions} {
+			maxDistance := suggestionThreshold(query)
+			// compare with checking every key
+			var want []nameCandidate
+			scratch := make([]int, 2*(len(query)+1))
+			for _, key := range keys {
+				if dist := editDistance(query, key, maxDistance, scratch); dist <= maxDistance {
+					want = insertCandidate(want, nameCandidate{key: key, distance: dist}, n)
+				}
+			}
+			got := b.names.closest(query, n, maxDistance)
+			if len(want) == 0 {
+				require.Empty(t, got, query)
+				continue
+			}
+			require.Equal(t, want, got, "%s %d", query, n)
+		}
+	}
+}
+
+func TestBaconator_CheckCastMember(t *testing.T) {
+	b := newFixtureBaconator(t)
+	require.NoError(t, b.CheckCastMember(DefaultCenter))
+	require.NoError(t, b.CheckCastMember("penelope cruz"))
+	require.EqualError(t, b.CheckCastMember("Tom Cruz"), `unknown cast member: "Tom Cruz". did you mean "Tom Cruise"?`)
+	require.EqualError(t, b.CheckCastMember("Nobody At All"), `unknown cast member: "Nobody At All"`)
+	require.EqualError(t, b.CheckCastMember(""), `unknown cast member: ""`)
+}
+
+func TestBaconator_search(t *testing.T) {
+	b := newFixtureBaconator(t)
+	names := func(res *searchResult) []string {
+		result := []string{}
+		for _, hit := range res.Results {
+			result = append(result, hit.Name)
+		}
+		return result
+	}
+	opts := &searchOptions{limit: 10}
+	require.Equal(t, []string{"Tom Cruise", "Top Gun", "Tom Hanks"}, names(b.search("to", opts)))
+	require.Equal(t, []string{"Tom Cruise", "Penélope Cruz"}, names(b.search("CR", opts)))
+	require.Equal(t, []string{"Big (film)"}, names(b.search("big", opts)))
+	require.Empty(t, names(b.search("zzz", opts)))
+
+	got := b.search("to", &searchOptions{limit: 1, offset: 1, nodeType: castNode})
+	require.Equal(t, 2, got.Total)
+	require.Equal(t, []string{"Tom Hanks"}, names(got))
+	require.Equal(t, 2, got.Results[0].Degree)
+	require.Equal(t, "cast", got.Results[0].Type)
+}
+
+func Test_normalizeName(t *testing.T) {
+	for in, want := range map[string]string{
+		"Kevin Bacon":           "kevin bacon",
+		"  KEVIN   bacon ":      "kevin bacon",
+		"Kevin Bacon (actor)":   "kevin bacon",
+		"Penélope Cruz":         "penelope cruz",
+		"Conan O'Brien":         "conan obrien",
+		"Jean-Claude Van Damme": "jean claude van damme",
+		"J.K. Simmons":          "jk simmons",
+		"(actor)":               "",
+	} {
+		require.Equal(t, want, normalizeName(in), in)
+	}
 }
 
 func TestCenter(t *testing.T) {
@@ -78,3 +773,39 @@ func newTestBaconator(t *testing.T) *Baconator {
 	require.NoError(t, file.Close())
 	return newTestBaconator(t)
 }
+
+// fixtureMovies returns a small hand made dataset. Everybody is linked to
+// Kevin Bacon except the cast of "Lonely Film".
+func fixtureMovies() map[string]*movie {
+	movies := []*movie{
+		{Title: "Footloose", Year: 1984, Cast: []string{"[[Kevin Bacon]]", "[[Lori Singer (actress)|Lori Singer]]", "[[John Lithgow]]"}},
+		{Title: "Apollo 13 (film)", Year: 1995, Cast: []string{"[[Kevin Bacon]]", "[[Tom Hanks]]", "[[Bill Paxton]]"}},
+		{Title: "Big (film)", Year: 1988, Cast: []string{"[[Tom Hanks]]", "[[Elizabeth Perkins]]"}},
+		{Title: "Vanilla Sky", Year: 2001, Cast: []string{"[[Tom Cruise]]", "[[Penélope Cruz]]", "[[Cameron Diaz]]"}},
+		{Title: "Top Gun", Year: 1986, Cast: []string{"[[Tom Cruise]]", "[[Kelly McGillis]]", "[[Val Kilmer]]"}},
+		{Title: "Witness (1985 film)", Year: 1985, Cast: []string{"[[Harrison Ford]]", "[[Kelly McGillis]]"}},
+		{Title: "A Few Good Men", Year: 1992, Cast: []string{"[[Tom Cruise]]", "[[Kevin Bacon]]", "[[Jack Nicholson]]"}},
+		{Title: "Lonely Film", Year: 1950, Cast: []string{"[[Sölo Äctor]]", "[[Other Loner]]"}},
+	}
+	result := make(map[string]*movie, len(movies))
+	for _, m := range movies {
+		result[m.Title] = m
+	}
+	return result
+}
+
+// edgeOfTomorrow links Tom Hanks and Tom Cruise a second way, through Bill Paxton, when it's added to
+// the fixture
+func edgeOfTomorrow() *movie {
+	return &movie{Title: "Edge of Tomorrow", Year: 2014, Cast: []string{"[[Tom Cruise]]", "[[Emily Blunt]]", "[[Bill Paxton]]"}}
+}
+
+// newFixtureBaconator builds the fixture with extra movies added
+func newFixtureBaconator(t *testing.T, extra ...*movie) *Baconator {
+	t.Helper()
+	movies := fixtureMovies()
+	for _, m := range extra {
+		movies[m.Title] = m
+	}
+	return buildBaconator(movies)
+}
@@ -0,0 +1,82 @@
+package baconator
+
+import (
+	"math/rand"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// BetweennessScores holds the estimated betweenness centrality of cast members and movies by name. A
+// score is the fraction of shortest paths between pairs of other cast members and movies that go
+// through the node, so the actors and films that bridge otherwise distant parts of the graph score the
+// highest. Zero scores are left out.
+type BetweennessScores struct {
+	// Samples is the number of nodes the shortest paths were sampled from
+	Samples int                `json:"samples"`
+	Cast    map[string]float64 `json:"cast"`
+	Movies  map[string]float64 `json:"movies"`
+}
+
+// ComputeBetweenness estimates betweenness centrality from the shortest paths of samples random nodes
+// picked with seed, stores the scores in b's node info and returns them. Zero or less samples means
+// every node, which gives exact scores but takes one search per node. workers is the number of searches
+// to run in parallel. Zero or less means one per CPU.
+func (b *Baconator) ComputeBetweenness(samples int, seed int64, workers int) *BetweennessScores {
+	size := len(b.NodeInfo)
+	if samples <= 0 || samples > size {
+		samples = size
+	}
+	sources := make([]graph.Node, samples)
+	for i, n := range rand.New(rand.NewSource(seed)).Perm(size)[:samples] { //nolint:gosec // not for security
+		sources[i] = graph.Node(n)
+	}
+	raw := b.Graph.Betweenness(sources, workers)
+	pairs := float64(size-1) * float64(size-2) / 2
+	scores := BetweennessScores{
+		Samples: samples,
+		Cast:    map[string]float64{},
+		Movies:  map[string]float64{},
+	}
+	for i, score := range raw {
+		if score == 0 {
+			continue
+		}
+		info := b.NodeInfo[i]
+		switch info.Type {
+		case castNode:
+			scores.Cast[info.Name] = score / pairs
+		case movieNode:
+			scores.Movies[info.Name] = score / pairs
+		}
+	}
+	b.SetBetweenness(&scores)
+	return &scores
+}
+
+// SetBetweenness stores scores in b's node info. Cast members and movies that aren't in scores get zero.
+func (b *Baconator) SetBetweenness(scores *BetweennessScores) {
+	for i := range b.NodeInfo {
+		info := &b.NodeInfo[i]
+		switch info.Type {
+		case castNode:
+			info.Betweenness = scores.Cast[info.Name]
+		case movieNode:
+			info.Betweenness = scores.Movies[info.Name]
+		}
+	}
+}
+
+// WriteFile writes the scores to filename as json
+func (s *BetweennessScores) WriteFile(filename string) error {
+	return writeJSONFile(filename, s)
+}
+
+// LoadBetweennessScores reads scores written by BetweennessScores.WriteFile
+func LoadBetweennessScores(filename string) (*BetweennessScores, error) {
+	var scores BetweennessScores
+	err := readJSONFile(filename, &scores)
+	if err != nil {
+		return nil, err
+	}
+	return &scores, nil
+}
@@ -0,0 +1,190 @@
+package baconator
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// defaultCenterCacheSize is the number of center results Server caches by default
+const defaultCenterCacheSize = 1000
+
+// centerCache is a concurrency safe LRU cache of center results. Pinned results are never evicted and
+// don't count toward the cache's size.
+type centerCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List
+	entries  map[graph.Node]*list.Element
+	pinned   map[graph.Node]*centerResult
+	stats    centerCacheStats
+
+	// calls holds the center searches in progress so that concurrent misses share one search
+	calls map[graph.Node]*centerCall
+}
+
+// centerCall is a center search in progress. done is closed once result is set or the search panicked.
+type centerCall struct {
+	done   chan struct{}
+	result *centerResult
+
+	// failed is true when the search panicked. panicked is the value it panicked with.
+	failed   bool
+	panicked interface{}
+}
+
+type centerCacheEntry struct {
+	node   graph.Node
+	result *centerResult
+}
+
+// centerCacheStats is the json body of the cache's stats
+type centerCacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Shared    uint64 `json:"shared"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	Pinned    int    `json:"pinned"`
+}
+
+// newCenterCache returns a cache holding up to capacity unpinned results. A capacity of zero or less
+// only holds pinned results.
+func newCenterCache(capacity int) *centerCache {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &centerCache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  map[graph.Node]*list.Element{},
+		pinned:   map[graph.Node]*centerResult{},
+		calls:    map[graph.Node]*centerCall{},
+	}
+}
+
+// getLocked returns the cached result for node and records a hit or miss
+func (c *centerCache) getLocked(node graph.Node) (*centerResult, bool) {
+	if res, ok := c.pinned[node]; ok {
+		c.stats.Hits++
+		return res, true
+	}
+	elem, ok := c.entries[node]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*centerCacheEntry).result, true
+}
+
+// getOrCompute returns the cached result for node. On a miss it caches and returns the result of
+// compute. A miss while node's result is already being computed waits for that result instead of
+// computing it again.
+func (c *centerCache) getOrCompute(node graph.Node, compute func() *centerResult) *centerResult {
+	c.mu.Lock()
+	if res, ok := c.getLocked(node); ok {
+		c.mu.Unlock()
+		return res
+	}
+	res, _ := c.share(node, compute, c.addLocked)
+	return res
+}
+
+// pinComputed pins the result of compute for node. It shares a computation already in progress for node.
+func (c *centerCache) pinComputed(node graph.Node, compute func() *centerResult) {
+	c.mu.Lock()
+	res, shared := c.share(node, compute, c.pinLocked)
+	if shared {
+		c.pin(node, res)
+	}
+}
+
+// share returns the result of the computation in progress for node and true, or when there isn't one,
+// runs compute, stores its result with store and returns it. c.mu must be locked when share is called,
+// and share unlocks it. When compute panics, nothing is stored and share panics with the same value
+// in the caller running compute and in every caller waiting for it.
+func (c *centerCache) share(node graph.Node, compute func() *centerResult, store func(graph.Node, *centerResult)) (*centerResult, bool) {
+	if call, ok := c.calls[node]; ok {
+		c.stats.Shared++
+		c.mu.Unlock()
+		<-call.done
+		if call.failed {
+			panic(call.panicked)
+		}
+		return call.result, true
+	}
+	call := &centerCall{done: make(chan struct{})}
+	c.calls[node] = call
+	c.mu.Unlock()
+	finished := false
+	defer func() {
+		if !finished {
+			call.failed = true
+			call.panicked = recover()
+		}
+		c.mu.Lock()
+		delete(c.calls, node)
+		if finished {
+			store(node, call.result)
+		}
+		c.mu.Unlock()
+		close(call.done)
+		if call.failed {
+			panic(call.panicked)
+		}
+	}()
+	call.result = compute()
+	finished = true
+	return call.result, false
+}
+
+// addLocked caches res for node, evicting the least recently used result when the cache is full
+func (c *centerCache) addLocked(node graph.Node, res *centerResult) {
+	if c.capacity == 0 {
+		return
+	}
+	if _, ok := c.pinned[node]; ok {
+		return
+	}
+	if elem, ok := c.entries[node]; ok {
+		elem.Value.(*centerCacheEntry).result = res
+		c.lru.MoveToFront(elem)
+		return
+	}
+	if c.lru.Len() >= c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*centerCacheEntry).node)
+		c.stats.Evictions++
+	}
+	c.entries[node] = c.lru.PushFront(&centerCacheEntry{node: node, result: res})
+}
+
+// pin caches res for node permanently
+func (c *centerCache) pin(node graph.Node, res *centerResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pinLocked(node, res)
+}
+
+func (c *centerCache) pinLocked(node graph.Node, res *centerResult) {
+	if elem, ok := c.entries[node]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, node)
+	}
+	c.pinned[node] = res
+}
+
+func (c *centerCache) statsSnapshot() centerCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	stats.Capacity = c.capacity
+	stats.Pinned = len(c.pinned)
+	return stats
+}
@@ -0,0 +1,104 @@
+package baconator
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// CenterRanking ranks the cast members of the largest connected component by their average distance to
+// everyone else. It is what Oracle of Bacon calls the center of the Hollywood universe.
+type CenterRanking struct {
+	// ComponentSize is the number of cast members in the largest connected component
+	ComponentSize int             `json:"component_size"`
+	Centers       []*RankedCenter `json:"centers"`
+}
+
+// RankedCenter is one cast member's place in a CenterRanking
+type RankedCenter struct {
+	Rank            int     `json:"rank"`
+	Name            string  `json:"name"`
+	AverageDistance float64 `json:"average_distance"`
+	TotalLinkable   int     `json:"total_linkable"`
+}
+
+// RankCenters calculates the center result of every cast member in the largest connected component and
+// ranks them from the lowest average distance to the highest. workers is the number of searches to run
+// in parallel. Zero or less means one per CPU. Each search runs on a single goroutine and reuses its
+// worker's level buffer.
+func (b *Baconator) RankCenters(workers int) *CenterRanking {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	var cast []graph.Node
+	for _, node := range b.Graph.LargestComponent() {
+		if b.NodeInfo[node].Type == castNode {
+			cast = append(cast, node)
+		}
+	}
+	g, _ := b.distanceGraph()
+	centers := make([]*RankedCenter, len(cast))
+	nodes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			levels := g.BorrowLevels()
+			defer g.ReturnLevels(levels)
+			for idx := range nodes {
+				g.FindLevelsInto(*levels, cast[idx], 1)
+				res := b.centerFromLevels(cast[idx], *levels)
+				centers[idx] = &RankedCenter{
+					Name:            b.NodeInfo[cast[idx]].Name,
+					AverageDistance: res.AvgDistance,
+					TotalLinkable:   res.Total,
+				}
+			}
+		}()
+	}
+	for idx := range cast {
+		nodes <- idx
+	}
+	close(nodes)
+	wg.Wait()
+
+	sort.Slice(centers, func(i, j int) bool {
+		if centers[i].AverageDistance != centers[j].AverageDistance {
+			return centers[i].AverageDistance < centers[j].AverageDistance
+		}
+		return centers[i].Name < centers[j].Name
+	})
+	for i, center := range centers {
+		center.Rank = i + 1
+	}
+	return &CenterRanking{
+		ComponentSize: len(cast),
+		Centers:       centers,
+	}
+}
+
+// top returns the n best ranked centers
+func (r *CenterRanking) top(n int) []*RankedCenter {
+	if n > len(r.Centers) {
+		n = len(r.Centers)
+	}
+	return r.Centers[:n]
+}
+
+// WriteFile writes the ranking to filename as json
+func (r *CenterRanking) WriteFile(filename string) error {
+	return writeJSONFile(filename, r)
+}
+
+// LoadCenterRanking reads a ranking written by CenterRanking.WriteFile
+func LoadCenterRanking(filename string) (*CenterRanking, error) {
+	var ranking CenterRanking
+	err := readJSONFile(filename, &ranking)
+	if err != nil {
+		return nil, err
+	}
+	return &ranking, nil
+}
@@ -1,29 +1,266 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/willabides/baconator"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "centers":
+			rankCenters(os.Args[2:])
+			return
+		case "stats":
+			printStats(os.Args[2:])
+			return
+		case "betweenness":
+			computeBetweenness(os.Args[2:])
+			return
+		case "pagerank":
+			computePageRank(os.Args[2:])
+			return
+		case "landmarks":
+			buildLandmarks(os.Args[2:])
+			return
+		}
+	}
+	serve()
+}
+
+func serve() {
 	var datafile string
 	var tcpAddr string
+	var centerCacheSize int
+	var precompute string
+	var centersFile string
+	var betweennessFile string
+	var pageRankFile string
+	var relatedDamping float64
+	var relatedTolerance float64
+	var landmarksFile string
+	var projected bool
+	var defaultCenter string
 	flag.StringVar(&datafile, "data", "data.txt.bz2", "path to data.txt.bz2")
 	flag.StringVar(&tcpAddr, "l", "localhost:8239", "tcp address to listen on")
+	flag.IntVar(&centerCacheSize, "center-cache", 1000, "number of /center results to cache")
+	flag.StringVar(&precompute, "precompute", "Kevin Bacon", "comma separated cast members whose /center results are computed at startup")
+	flag.StringVar(&centersFile, "centers", "", "path to a ranking written by `baconator centers` to serve at /centers/top")
+	flag.StringVar(&betweennessFile, "betweenness", "", "path to scores written by `baconator betweenness`")
+	flag.StringVar(&pageRankFile, "pagerank", "", "path to scores written by `baconator pagerank`")
+	flag.Float64Var(&relatedDamping, "related-damping", 0.85, "damping of the personalized PageRank behind /related")
+	flag.Float64Var(&relatedTolerance, "related-tolerance", 1e-5, "tolerance of the personalized PageRank behind /related")
+	flag.StringVar(&landmarksFile, "landmarks", "", "path to landmarks written by `baconator landmarks`")
+	flag.BoolVar(&projected, "projected", false, "answer distance queries with a graph of actors linked by shared movies")
+	flag.StringVar(&defaultCenter, "default-center", baconator.DefaultCenter, "cast member that /actor reports Bacon numbers relative to")
 	flag.Parse()
+	opts := []baconator.ServerOption{
+		baconator.WithCenterCacheSize(centerCacheSize),
+		baconator.WithDefaultCenter(defaultCenter),
+		baconator.WithRelatedPageRank(relatedDamping, relatedTolerance),
+	}
+	if centersFile != "" {
+		log.Printf("loading center ranking from %s", centersFile)
+		ranking, err := baconator.LoadCenterRanking(centersFile)
+		if err != nil {
+			log.Fatalf("error loading center ranking: %v", err)
+		}
+		opts = append(opts, baconator.WithCenterRanking(ranking))
+	}
+	b := loadBaconator(datafile)
+	if err := b.CheckCastMember(defaultCenter); err != nil {
+		log.Fatalf("invalid -default-center: %v", err)
+	}
+	if betweennessFile != "" {
+		log.Printf("loading betweenness scores from %s", betweennessFile)
+		scores, err := baconator.LoadBetweennessScores(betweennessFile)
+		if err != nil {
+			log.Fatalf("error loading betweenness scores: %v", err)
+		}
+		b.SetBetweenness(scores)
+	}
+	if pageRankFile != "" {
+		log.Printf("loading pagerank scores from %s", pageRankFile)
+		scores, err := baconator.LoadPageRankScores(pageRankFile)
+		if err != nil {
+			log.Fatalf("error loading pagerank scores: %v", err)
+		}
+		b.SetPageRank(scores)
+	}
+	if landmarksFile != "" {
+		log.Printf("loading landmarks from %s", landmarksFile)
+		err := b.LoadLandmarks(landmarksFile)
+		if err != nil {
+			log.Fatalf("error loading landmarks: %v", err)
+		}
+	}
+	if projected {
+		buildProjection(b)
+	}
+	s := baconator.NewServer(b, opts...)
+	if precompute != "" {
+		log.Printf("precomputing centers for %s", precompute)
+		err := s.PrecomputeCenters(strings.Split(precompute, ",")...)
+		if err != nil {
+			log.Fatalf("error precomputing centers: %v", err)
+		}
+	}
+	log.Printf("Listening at %s", tcpAddr)
+	err := http.ListenAndServe(tcpAddr, s)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// rankCenters runs the `baconator centers` subcommand
+func rankCenters(args []string) {
+	var datafile string
+	var output string
+	var workers int
+	var projected bool
+	flags := flag.NewFlagSet("centers", flag.ExitOnError)
+	flags.StringVar(&datafile, "data", "data.txt.bz2", "path to data.txt.bz2")
+	flags.StringVar(&output, "o", "centers.json", "file to write the ranking to")
+	flags.IntVar(&workers, "workers", 0, "number of searches to run in parallel. defaults to the number of CPUs")
+	flags.BoolVar(&projected, "projected", false, "search a graph of actors linked by shared movies")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := loadBaconator(datafile)
+	if projected {
+		buildProjection(b)
+	}
+	log.Printf("ranking centers")
+	start := time.Now()
+	ranking := b.RankCenters(workers)
+	log.Printf("ranked %d centers in %s", len(ranking.Centers), time.Since(start))
+	err = ranking.WriteFile(output)
+	if err != nil {
+		log.Fatalf("error writing center ranking: %v", err)
+	}
+	log.Printf("wrote center ranking to %s", output)
+}
+
+// computeBetweenness runs the `baconator betweenness` subcommand
+func computeBetweenness(args []string) {
+	var datafile string
+	var output string
+	var samples int
+	var seed int64
+	var workers int
+	flags := flag.NewFlagSet("betweenness", flag.ExitOnError)
+	flags.StringVar(&datafile, "data", "data.txt.bz2", "path to data.txt.bz2")
+	flags.StringVar(&output, "o", "betweenness.json", "file to write the scores to")
+	flags.IntVar(&samples, "samples", 1000, "number of nodes to sample shortest paths from. 0 samples every node")
+	flags.Int64Var(&seed, "seed", 1, "random seed for picking samples")
+	flags.IntVar(&workers, "workers", 0, "number of searches to run in parallel. defaults to the number of CPUs")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := loadBaconator(datafile)
+	log.Printf("computing betweenness")
+	start := time.Now()
+	scores := b.ComputeBetweenness(samples, seed, workers)
+	log.Printf("computed betweenness from %d samples in %s", scores.Samples, time.Since(start))
+	err = scores.WriteFile(output)
+	if err != nil {
+		log.Fatalf("error writing betweenness scores: %v", err)
+	}
+	log.Printf("wrote betweenness scores to %s", output)
+}
+
+// computePageRank runs the `baconator pagerank` subcommand
+func computePageRank(args []string) {
+	var datafile string
+	var output string
+	var damping float64
+	var tolerance float64
+	flags := flag.NewFlagSet("pagerank", flag.ExitOnError)
+	flags.StringVar(&datafile, "data", "data.txt.bz2", "path to data.txt.bz2")
+	flags.StringVar(&output, "o", "pagerank.json", "file to write the scores to")
+	flags.Float64Var(&damping, "damping", 0.85, "probability that the random walk follows a link instead of jumping to a random node")
+	flags.Float64Var(&tolerance, "tolerance", 1e-6, "total change in the scores that counts as converged")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := loadBaconator(datafile)
+	log.Printf("computing pagerank")
+	start := time.Now()
+	scores := b.ComputePageRank(damping, tolerance)
+	log.Printf("computed pagerank in %d iterations in %s", scores.Iterations, time.Since(start))
+	err = scores.WriteFile(output)
+	if err != nil {
+		log.Fatalf("error writing pagerank scores: %v", err)
+	}
+	log.Printf("wrote pagerank scores to %s", output)
+}
+
+// buildLandmarks runs the `baconator landmarks` subcommand
+func buildLandmarks(args []string) {
+	var datafile string
+	var output string
+	flags := flag.NewFlagSet("landmarks", flag.ExitOnError)
+	flags.StringVar(&datafile, "data", "data.txt.bz2", "path to data.txt.bz2")
+	flags.StringVar(&output, "o", "landmarks.gob", "file to write the landmarks to")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := loadBaconator(datafile)
+	log.Printf("building landmarks")
+	start := time.Now()
+	b.BuildLandmarks()
+	log.Printf("built landmarks in %s", time.Since(start))
+	err = b.WriteLandmarks(output)
+	if err != nil {
+		log.Fatalf("error writing landmarks: %v", err)
+	}
+	log.Printf("wrote landmarks to %s", output)
+}
+
+// printStats runs the `baconator stats` subcommand
+func printStats(args []string) {
+	var datafile string
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	flags.StringVar(&datafile, "data", "data.txt.bz2", "path to data.txt.bz2")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := loadBaconator(datafile)
+	stats := b.Stats()
+	stats.Diameter = b.Diameter()
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(stats)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func buildProjection(b *baconator.Baconator) {
+	log.Printf("building projected graph")
+	start := time.Now()
+	b.BuildProjection()
+	log.Printf("built projected graph in %s", time.Since(start))
+}
+
+func loadBaconator(datafile string) *baconator.Baconator {
 	b := &baconator.Baconator{}
 	log.Printf("loading data from %s", datafile)
 	err := b.LoadFromDatafile(datafile)
 	if err != nil {
 		log.Fatalf("error loading data: %v", err)
 	}
-	s := baconator.NewServer(b)
-	log.Printf("Listening at %s", tcpAddr)
-	err = http.ListenAndServe(tcpAddr, s)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return b
 }
@@ -0,0 +1,70 @@
+package baconator
+
+import (
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// buildComponentCast counts the cast members in each of the graph's components
+func (b *Baconator) buildComponentCast() []int {
+	components := b.Graph.Components()
+	counts := make([]int, components.Count())
+	for _, info := range b.NodeInfo {
+		if info.Type == castNode {
+			counts[components.Component(info.Node)]++
+		}
+	}
+	return counts
+}
+
+type componentResult struct {
+	Resolved   *nameMatch `json:"resolved"`
+	Component  int        `json:"component"`
+	CastCount  int        `json:"cast_count"`
+	MovieCount int        `json:"movie_count"`
+
+	// Largest is whether this is the largest component. Component 0 is always the largest.
+	Largest bool `json:"largest"`
+}
+
+// component describes the connected component that node belongs to
+func (b *Baconator) component(node graph.Node) *componentResult {
+	b.loadIndexes()
+	components := b.Graph.Components()
+	id := components.Component(node)
+	return &componentResult{
+		Component:  id,
+		CastCount:  b.componentCast[id],
+		MovieCount: components.Size(id) - b.componentCast[id],
+		Largest:    id == 0,
+	}
+}
+
+// GraphStats summarizes the graph of cast members and movies
+type GraphStats struct {
+	Cast       int `json:"cast"`
+	Movies     int `json:"movies"`
+	Components int `json:"components"`
+
+	// LargestComponentCast and LargestComponentMovies are the size of the largest connected component.
+	// Every cast member in it can be linked to every other.
+	LargestComponentCast   int `json:"largest_component_cast"`
+	LargestComponentMovies int `json:"largest_component_movies"`
+
+	// Diameter is only set by `baconator stats` because it takes a couple of searches to find
+	Diameter *DiameterStats `json:"diameter,omitempty"`
+}
+
+// Stats returns stats about b's graph
+func (b *Baconator) Stats() *GraphStats {
+	b.loadIndexes()
+	stats := GraphStats{
+		Cast:       len(b.CastNodes),
+		Movies:     len(b.MovieNodes),
+		Components: len(b.componentCast),
+	}
+	if stats.Components > 0 {
+		stats.LargestComponentCast = b.componentCast[0]
+		stats.LargestComponentMovies = b.Graph.Components().Size(0) - b.componentCast[0]
+	}
+	return &stats
+}
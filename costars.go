@@ -0,0 +1,82 @@
+package baconator
+
+import (
+	"sort"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+type costarMovie struct {
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+}
+
+type costar struct {
+	Name           string         `json:"name"`
+	Collaborations int            `json:"collaborations"`
+	Movies         []*costarMovie `json:"movies"`
+}
+
+type costarsResult struct {
+	Resolved *nameMatch `json:"resolved"`
+	Total    int        `json:"total"`
+	Costars  []*costar  `json:"costars"`
+}
+
+// costars finds every cast member who shares a movie with node. They are sorted by the number of movies
+// they share, then by name, and each one's shared movies are sorted by year. It returns limit of them
+// starting at offset.
+func (b *Baconator) costars(node graph.Node, limit, offset int) *costarsResult {
+	byNode := map[graph.Node]*costar{}
+	for _, film := range b.Graph.NodeNeighbors(node) {
+		title := b.NodeInfo[film].Name
+		shared := &costarMovie{
+			Title: title,
+		}
+		if m := b.Movies[title]; m != nil {
+			shared.Year = m.Year
+		}
+		for _, member := range b.Graph.NodeNeighbors(film) {
+			if member == node {
+				continue
+			}
+			c := byNode[member]
+			if c == nil {
+				c = &costar{
+					Name: b.NodeInfo[member].Name,
+				}
+				byNode[member] = c
+			}
+			c.Collaborations++
+			c.Movies = append(c.Movies, shared)
+		}
+	}
+	costars := make([]*costar, 0, len(byNode))
+	for _, c := range byNode {
+		sort.Slice(c.Movies, func(i, j int) bool {
+			if c.Movies[i].Year != c.Movies[j].Year {
+				return c.Movies[i].Year < c.Movies[j].Year
+			}
+			return c.Movies[i].Title < c.Movies[j].Title
+		})
+		costars = append(costars, c)
+	}
+	sort.Slice(costars, func(i, j int) bool {
+		if costars[i].Collaborations != costars[j].Collaborations {
+			return costars[i].Collaborations > costars[j].Collaborations
+		}
+		return costars[i].Name < costars[j].Name
+	})
+	result := costarsResult{
+		Total:   len(costars),
+		Costars: []*costar{},
+	}
+	if offset < len(costars) {
+		costars = costars[offset:]
+		if len(costars) > limit {
+			costars = costars[:limit]
+		}
+		result.Costars = costars
+	}
+	return &result
+}
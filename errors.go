@@ -0,0 +1,132 @@
+package baconator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// error codes returned in apiError.Code
+const (
+	codeUnknownCast      = "unknown_cast"
+	codeUnknownMovie     = "unknown_movie"
+	codeMissingParam     = "missing_param"
+	codeInvalidParam     = "invalid_param"
+	codeNoPath           = "no_path"
+	codeMaxHopsExceeded  = "max_hops_exceeded"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeUnavailable      = "unavailable"
+	codeInternal         = "internal_error"
+)
+
+// apiError is the error body returned by Server
+type apiError struct {
+	Status      int      `json:"-"`
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	Param       string   `json:"param,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+type errorResponse struct {
+	Error *apiError `json:"error"`
+}
+
+func missingParamError(param string) *apiError {
+	return &apiError{
+		Status:  http.StatusBadRequest,
+		Code:    codeMissingParam,
+		Message: param + " is a required query parameter",
+		Param:   param,
+	}
+}
+
+func invalidParamError(param, msg string) *apiError {
+	return &apiError{
+		Status:  http.StatusBadRequest,
+		Code:    codeInvalidParam,
+		Message: msg,
+		Param:   param,
+	}
+}
+
+// castError converts an error from resolving the cast member in param to an apiError
+func castError(err error, param string) error {
+	var castErr *unknownCastError
+	if !errors.As(err, &castErr) {
+		return err
+	}
+	return &apiError{
+		Status:      http.StatusNotFound,
+		Code:        codeUnknownCast,
+		Message:     castErr.Error(),
+		Param:       param,
+		Suggestions: castErr.suggestions,
+	}
+}
+
+// movieError converts an error from resolving the movie in param to an apiError
+func movieError(err error, param string) error {
+	var movieErr *unknownMovieError
+	if !errors.As(err, &movieErr) {
+		return err
+	}
+	return &apiError{
+		Status:  http.StatusNotFound,
+		Code:    codeUnknownMovie,
+		Message: movieErr.Error(),
+		Param:   param,
+	}
+}
+
+// writeError writes err as a json error body. Errors that aren't an *apiError
+// are reported as internal errors.
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		apiErr = &apiError{
+			Status:  http.StatusInternalServerError,
+			Code:    codeInternal,
+			Message: err.Error(),
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(apiErr.Status)
+	err = json.NewEncoder(w).Encode(&errorResponse{Error: apiErr})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// requiredParam returns the value of a required query parameter
+func requiredParam(req *http.Request, name string) (string, error) {
+	val := req.URL.Query().Get(name)
+	if val == "" {
+		return "", missingParamError(name)
+	}
+	return val, nil
+}
+
+// intParam parses an optional integer query parameter. A negative max means no maximum.
+func intParam(req *http.Request, name string, defaultVal, min, max int) (int, error) {
+	val := req.URL.Query().Get(name)
+	if val == "" {
+		return defaultVal, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < min || (max >= 0 && n > max) {
+		msg := name + " must be an integer greater than or equal to " + strconv.Itoa(min)
+		if max >= 0 {
+			msg += " and less than or equal to " + strconv.Itoa(max)
+		}
+		return 0, invalidParamError(name, msg)
+	}
+	return n, nil
+}
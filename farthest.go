@@ -0,0 +1,71 @@
+package baconator
+
+import (
+	"sort"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+type farthestResult struct {
+	Resolved *nameMatch `json:"resolved"`
+
+	// BaconNumber is the number of movies between the resolved cast member and each of Cast
+	BaconNumber int      `json:"bacon_number"`
+	Total       int      `json:"total"`
+	Cast        []string `json:"cast"`
+}
+
+func (b *Baconator) isCast(node graph.Node) bool {
+	return b.NodeInfo[node].Type == castNode
+}
+
+// farthest finds the cast members that are the most movies away from center. It returns at most
+// limit of them in alphabetical order.
+func (b *Baconator) farthest(center graph.Node, limit int) *farthestResult {
+	g, levelsPerHop := b.distanceGraph()
+	nodes, distance := g.FarthestNodes(center, b.isCast)
+	result := farthestResult{
+		BaconNumber: distance / levelsPerHop,
+		Total:       len(nodes),
+		Cast:        make([]string, len(nodes)),
+	}
+	for i, node := range nodes {
+		result.Cast[i] = b.NodeInfo[node].Name
+	}
+	sort.Strings(result.Cast)
+	if len(result.Cast) > limit {
+		result.Cast = result.Cast[:limit]
+	}
+	return &result
+}
+
+// DiameterStats describes the longest link in the largest connected component
+type DiameterStats struct {
+	// BaconNumber is the number of movies between From and To
+	BaconNumber int    `json:"bacon_number"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+}
+
+// Diameter estimates the longest link between two cast members of the largest connected component with
+// a double sweep search. The true diameter may be longer, but rarely is.
+func (b *Baconator) Diameter() *DiameterStats {
+	var start graph.Node
+	found := false
+	for _, node := range b.Graph.LargestComponent() {
+		if b.isCast(node) {
+			start, found = node, true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+	g, levelsPerHop := b.distanceGraph()
+	distance, from, to := g.ApproximateDiameter(start, b.isCast)
+	return &DiameterStats{
+		BaconNumber: distance / levelsPerHop,
+		From:        b.NodeInfo[from].Name,
+		To:          b.NodeInfo[to].Name,
+	}
+}
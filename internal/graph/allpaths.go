@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"math"
+	"math/bits"
+)
+
+// AllShortestPaths finds every shortest path from source to dest.
+//  It returns up to limit of the paths along with the total number of shortest paths. The total saturates
+//  at math.MaxUint64. Like FindPath, the search assumes that every edge has a matching edge in the
+//  opposite direction. opts can restrict which nodes the paths may use.
+func (g *Graph) AllShortestPaths(maxPathLength int, source, dest Node, limit int, opts ...PathOption) ([][]Node, uint64, PathOutcome) {
+	if maxPathLength <= 0 {
+		maxPathLength = defaultMaxPathLength
+	}
+	size := len(g.edgeIndex) - 1
+	if source >= Node(size) || dest >= Node(size) {
+		return nil, 0, InvalidNode
+	}
+	filter := g.newSearchFilter(opts)
+	defer g.releaseSearchFilter(filter)
+	if filter.blocks(source) || filter.blocks(dest) || !g.Components().Connected(source, dest) {
+		return nil, 0, NoPathExists
+	}
+	if outcome, ok := filter.boundOutcome(source, dest, maxPathLength); ok {
+		return nil, 0, outcome
+	}
+	if source == dest {
+		var paths [][]Node
+		if limit > 0 {
+			paths = [][]Node{{source}}
+		}
+		return paths, 1, PathFound
+	}
+
+	srcFrontier := g.borrowLevelSlice()
+	defer g.returnLevelSlice(srcFrontier)
+	destFrontier := g.borrowLevelSlice()
+	defer g.returnLevelSlice(destFrontier)
+	scratchBuffer := g.borrowLevelSlice()
+	defer g.returnLevelSlice(scratchBuffer)
+	meetings := g.borrowLevelSlice()
+	defer g.returnLevelSlice(meetings)
+
+	srcLevels := g.borrowParentsMap()
+	defer g.returnParentsMap(srcLevels)
+	destLevels := g.borrowParentsMap()
+	defer g.returnParentsMap(destLevels)
+
+	*srcFrontier = append(*srcFrontier, source)
+	*destFrontier = append(*destFrontier, dest)
+	srcLevels.setLevel(source, 0)
+	destLevels.setLevel(dest, 0)
+	srcDepth, destDepth := 0, 0
+	for len(*meetings) == 0 {
+		if len(*srcFrontier) == 0 || len(*destFrontier) == 0 {
+			return nil, 0, NoPathExists
+		}
+		if srcDepth+destDepth+2 > maxPathLength {
+			return nil, 0, MaxPathLengthExceeded
+		}
+		// expand whichever side has the smaller frontier
+		if len(*srcFrontier) <= len(*destFrontier) {
+			srcDepth++
+			g.expandLevel(srcFrontier, scratchBuffer, meetings, srcLevels, destLevels, srcDepth, filter)
+			continue
+		}
+		destDepth++
+		g.expandLevel(destFrontier, scratchBuffer, meetings, destLevels, srcLevels, destDepth, filter)
+	}
+
+	// Every shortest path passes through exactly one meeting node, so paths can be counted and
+	// enumerated one meeting node at a time.
+	sortNodesBYOB(*meetings, (*scratchBuffer)[:cap(*scratchBuffer)])
+	spc := shortestPathCounter{
+		g:          g,
+		srcLevels:  srcLevels,
+		destLevels: destLevels,
+		srcCounts:  map[Node]uint64{source: 1},
+		destCounts: map[Node]uint64{dest: 1},
+	}
+	var total uint64
+	for _, m := range *meetings {
+		total = saturatingAdd(total, saturatingMul(spc.count(m, srcLevels, spc.srcCounts), spc.count(m, destLevels, spc.destCounts)))
+	}
+	var paths [][]Node
+	if limit > 0 {
+		pe := pathEnumerator{
+			shortestPathCounter: spc,
+			path:                make([]Node, srcDepth+destDepth+1),
+			limit:               limit,
+		}
+		for _, m := range *meetings {
+			if len(pe.paths) >= limit {
+				break
+			}
+			pe.meeting = m
+			pe.path[srcLevels.getLevel(m)] = m
+			pe.walkSource(m)
+		}
+		paths = pe.paths
+	}
+	return paths, total, PathFound
+}
+
+// expandLevel adds every unvisited neighbor of frontier to levels at the given depth and replaces
+// frontier with them. Neighbors that have also been visited by otherLevels are added to meetings.
+func (g *Graph) expandLevel(frontier, scratchBuffer, meetings *[]Node, levels, otherLevels *parentsMap, depth int, filter *searchFilter) {
+	*scratchBuffer = (*scratchBuffer)[:0]
+	for _, node := range *frontier {
+		for _, neighbor := range g.NodeNeighbors(node) {
+			if levels.contains(neighbor) || filter.blocks(neighbor) {
+				continue
+			}
+			levels.setLevel(neighbor, depth)
+			*scratchBuffer = append(*scratchBuffer, neighbor)
+			if otherLevels.contains(neighbor) {
+				*meetings = append(*meetings, neighbor)
+			}
+		}
+	}
+	*frontier, *scratchBuffer = *scratchBuffer, *frontier
+}
+
+type shortestPathCounter struct {
+	g                     *Graph
+	srcLevels, destLevels *parentsMap
+	srcCounts, destCounts map[Node]uint64
+}
+
+// count returns the number of shortest paths from node back to the level 0 node of levels
+func (c *shortestPathCounter) count(node Node, levels *parentsMap, counts map[Node]uint64) uint64 {
+	if n, ok := counts[node]; ok {
+		return n
+	}
+	var n uint64
+	level := levels.getLevel(node)
+	for _, neighbor := range c.g.NodeNeighbors(node) {
+		if levels.contains(neighbor) && levels.getLevel(neighbor) == level-1 {
+			n = saturatingAdd(n, c.count(neighbor, levels, counts))
+		}
+	}
+	counts[node] = n
+	return n
+}
+
+type pathEnumerator struct {
+	shortestPathCounter
+	path    []Node
+	paths   [][]Node
+	limit   int
+	meeting Node
+}
+
+// walkSource fills path from node back to the source and then walks from the meeting node to dest
+func (e *pathEnumerator) walkSource(node Node) {
+	level := e.srcLevels.getLevel(node)
+	if level == 0 {
+		e.walkDest(e.meeting)
+		return
+	}
+	for _, neighbor := range e.g.NodeNeighbors(node) {
+		if len(e.paths) >= e.limit {
+			return
+		}
+		if !e.srcLevels.contains(neighbor) || e.srcLevels.getLevel(neighbor) != level-1 {
+			continue
+		}
+		e.path[level-1] = neighbor
+		e.walkSource(neighbor)
+	}
+}
+
+// walkDest fills path from node forward to dest and records the completed path
+func (e *pathEnumerator) walkDest(node Node) {
+	level := e.destLevels.getLevel(node)
+	if level == 0 {
+		p := make([]Node, len(e.path))
+		copy(p, e.path)
+		e.paths = append(e.paths, p)
+		return
+	}
+	idx := len(e.path) - level
+	for _, neighbor := range e.g.NodeNeighbors(node) {
+		if len(e.paths) >= e.limit {
+			return
+		}
+		if !e.destLevels.contains(neighbor) || e.destLevels.getLevel(neighbor) != level-1 {
+			continue
+		}
+		e.path[idx] = neighbor
+		e.walkDest(neighbor)
+	}
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+func saturatingMul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -32,3 +33,137 @@ func BenchmarkGraph_FindPath(b *testing.B) {
 
 	_ = globalPathLen
 }
+
+func BenchmarkGraph_FindPath_priority(b *testing.B) {
+	var path []Node
+	g := graphFromGob(b, "100k_graph.gob")
+	priority := func(node Node) int64 {
+		return int64(node % 97)
+	}
+	b.Run("PriorityFunc", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			src := Node(i%len(g.edgeIndex) - 2)
+			dest := Node(i/2%len(g.edgeIndex) - 2)
+			g.FindPath(&path, 999, src, dest, priority)
+			globalPathLen = len(path)
+		}
+		b.ReportAllocs()
+	})
+
+	order := g.OrderNeighbors(priority)
+	opt := WithNeighborOrder(order)
+	b.Run("NeighborOrder", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			src := Node(i%len(g.edgeIndex) - 2)
+			dest := Node(i/2%len(g.edgeIndex) - 2)
+			g.FindPath(&path, 999, src, dest, nil, opt)
+			globalPathLen = len(path)
+		}
+		b.ReportAllocs()
+	})
+}
+
+func BenchmarkGraph_FindPath_landmarks(b *testing.B) {
+	var path []Node
+	for _, td := range []struct {
+		name, filename string
+	}{
+		{name: "100k", filename: "100k_graph.gob"},
+		{name: "1000k", filename: "1MM_graph.gob"},
+	} {
+		g := graphFromGob(b, td.filename)
+		landmarks := WithLandmarks(g.BuildLandmarks(16))
+		order := WithNeighborOrder(g.OrderNeighbors(func(node Node) int64 {
+			return int64(node % 7)
+		}))
+		for _, maxPathLength := range []int{999, 5} {
+			for _, opts := range []struct {
+				name string
+				opts []PathOption
+			}{
+				{name: "none"},
+				{name: "landmarks", opts: []PathOption{landmarks}},
+				{name: "order", opts: []PathOption{order}},
+				{name: "order+landmarks", opts: []PathOption{order, landmarks}},
+			} {
+				b.Run(fmt.Sprintf("%s/%d/%s", td.name, maxPathLength, opts.name), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						src := Node(i%len(g.edgeIndex) - 2)
+						dest := Node(i/2%len(g.edgeIndex) - 2)
+						g.FindPath(&path, maxPathLength, src, dest, nil, opts.opts...)
+						globalPathLen = len(path)
+					}
+					b.ReportAllocs()
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkLandmarks_DistanceBounds(b *testing.B) {
+	g := graphFromGob(b, "1MM_graph.gob")
+	landmarks := g.BuildLandmarks(16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src := Node(i%len(g.edgeIndex) - 2)
+		dest := Node(i/2%len(g.edgeIndex) - 2)
+		globalPathLen, _, _ = landmarks.DistanceBounds(src, dest)
+	}
+	b.ReportAllocs()
+}
+
+func BenchmarkGraph_FindLevels(b *testing.B) {
+	g := graphFromGob(b, "1MM_graph.gob")
+	levels := make([]uint8, len(g.edgeIndex)-1)
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.FindLevelsInto(levels, Node(i%len(levels)), workers)
+			}
+			b.ReportAllocs()
+		})
+	}
+}
+
+func BenchmarkGraph_Betweenness(b *testing.B) {
+	g := graphFromGob(b, "1MM_graph.gob")
+	sources := make([]Node, 8)
+	for i := range sources {
+		sources[i] = Node(i * 1000)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Betweenness(sources, 0)
+	}
+	b.ReportAllocs()
+}
+
+func BenchmarkGraph_PageRank(b *testing.B) {
+	g := graphFromGob(b, "1MM_graph.gob")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.PageRank(&PageRankOptions{MaxIterations: 10})
+	}
+	b.ReportAllocs()
+}
+
+func BenchmarkGraph_PersonalizedPageRank(b *testing.B) {
+	g := graphFromGob(b, "1MM_graph.gob")
+	for _, tolerance := range []float64{1e-4, 1e-5, 1e-6} {
+		b.Run(fmt.Sprintf("tolerance=%g", tolerance), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.PersonalizedPageRank(Node(i*1000%(len(g.edgeIndex)-1)), &PageRankOptions{Tolerance: tolerance})
+			}
+			b.ReportAllocs()
+		})
+	}
+}
+
+func BenchmarkGraph_FindCuts(b *testing.B) {
+	g := graphFromGob(b, "1MM_graph.gob")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.FindCuts(nil)
+	}
+	b.ReportAllocs()
+}
@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Components labels every node with the connected component it belongs to. Components are numbered
+// from the largest to the smallest, so the largest component is always 0. Components of the same size
+// are numbered by their lowest node.
+type Components struct {
+	labels []uint32
+	sizes  []int
+}
+
+// Components returns g's connected components. They are calculated on the first call and cached.
+func (g *Graph) Components() *Components {
+	if c, ok := g.components.Load().(*Components); ok {
+		return c
+	}
+	c := g.labelComponents()
+	g.components.Store(c)
+	return c
+}
+
+func (g *Graph) labelComponents() *Components {
+	size := len(g.edgeIndex) - 1
+	visited := g.borrowBitset()
+	defer g.returnBitset(visited)
+	queue := g.borrowLevelSlice()
+	defer g.returnLevelSlice(queue)
+
+	// label in node order first, then renumber by size
+	labels := make([]uint32, size)
+	var sizes []int
+	for n := 0; n < size; n++ {
+		if visited.contains(Node(n)) {
+			continue
+		}
+		label := uint32(len(sizes))
+		*queue = append((*queue)[:0], Node(n))
+		visited.set(Node(n))
+		for i := 0; i < len(*queue); i++ {
+			node := (*queue)[i]
+			labels[node] = label
+			for _, neighbor := range g.NodeNeighbors(node) {
+				if !visited.contains(neighbor) {
+					visited.set(neighbor)
+					*queue = append(*queue, neighbor)
+				}
+			}
+		}
+		sizes = append(sizes, len(*queue))
+	}
+
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	// components are already ordered by their lowest node, so a stable sort keeps that order for ties
+	sort.SliceStable(order, func(i, j int) bool {
+		return sizes[order[i]] > sizes[order[j]]
+	})
+	renumber := make([]uint32, len(sizes))
+	c := Components{
+		labels: labels,
+		sizes:  make([]int, len(sizes)),
+	}
+	for id, old := range order {
+		renumber[old] = uint32(id)
+		c.sizes[id] = sizes[old]
+	}
+	for n, label := range labels {
+		labels[n] = renumber[label]
+	}
+	return &c
+}
+
+// Count returns the number of components
+func (c *Components) Count() int {
+	return len(c.sizes)
+}
+
+// Component returns the id of node's component
+func (c *Components) Component(node Node) int {
+	return int(c.labels[node])
+}
+
+// Size returns the number of nodes in component id
+func (c *Components) Size(id int) int {
+	return c.sizes[id]
+}
+
+// Connected returns whether a and b are in the same component
+func (c *Components) Connected(a, b Node) bool {
+	return c.labels[a] == c.labels[b]
+}
+
+// LargestComponent returns the nodes of the largest connected component in ascending order. When
+// several components are the largest, it returns the one with the lowest node.
+func (g *Graph) LargestComponent() []Node {
+	c := g.Components()
+	if c.Count() == 0 {
+		return nil
+	}
+	nodes := make([]Node, 0, c.Size(0))
+	for n, label := range c.labels {
+		if label == 0 {
+			nodes = append(nodes, Node(n))
+		}
+	}
+	return nodes
+}
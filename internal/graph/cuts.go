@@ -0,0 +1,151 @@
+package graph
+
+// Cuts describes how removing each node would split its connected component. Nodes whose removal
+//  splits their component are articulation points.
+type Cuts struct {
+	// pieces is the number of pieces each node's component splits into without it
+	pieces []uint32
+
+	// stranded is the weight of every piece but the heaviest
+	stranded []int
+}
+
+// FindCuts finds how removing each node would split its connected component with Tarjan's articulation
+//  point algorithm. Pieces are measured by the total weight of their nodes. A nil weight gives every node
+//  a weight of 1.
+func (g *Graph) FindCuts(weight func(Node) int) *Cuts {
+	if weight == nil {
+		weight = func(Node) int { return 1 }
+	}
+	size := len(g.edgeIndex) - 1
+	c := Cuts{
+		pieces:   make([]uint32, size),
+		stranded: make([]int, size),
+	}
+	s := cutSearch{
+		g:         g,
+		weight:    weight,
+		disc:      make([]uint32, size),
+		low:       make([]uint32, size),
+		parent:    make([]Node, size),
+		next:      make([]int, size),
+		subtree:   make([]int, size),
+		separated: make([]int, size),
+		heaviest:  make([]int, size),
+	}
+	for n := 0; n < size; n++ {
+		root := Node(n)
+		if s.disc[root] != 0 {
+			continue
+		}
+		s.search(root, c.pieces)
+		componentWeight := s.subtree[root]
+		for _, node := range s.component {
+			// the rest of the component stays connected through node's parent
+			heaviest := s.heaviest[node]
+			total := s.separated[node]
+			if node != root {
+				c.pieces[node]++
+				rest := componentWeight - weight(node) - s.separated[node]
+				total += rest
+				if rest > heaviest {
+					heaviest = rest
+				}
+			}
+			c.stranded[node] = total - heaviest
+		}
+	}
+	return &c
+}
+
+// IsArticulation returns whether removing node would split its connected component
+func (c *Cuts) IsArticulation(node Node) bool {
+	return c.pieces[node] > 1
+}
+
+// Pieces returns the number of pieces node's connected component would split into without node
+func (c *Cuts) Pieces(node Node) int {
+	return int(c.pieces[node])
+}
+
+// Stranded returns the weight of the nodes that removing node would cut off from the heaviest piece of
+//  its connected component. They can only reach the rest of the component through node.
+func (c *Cuts) Stranded(node Node) int {
+	return c.stranded[node]
+}
+
+// cutSearch holds the state of FindCuts' depth first search
+type cutSearch struct {
+	g      *Graph
+	weight func(Node) int
+
+	// disc is the order each node was discovered in starting from 1. Zero means undiscovered.
+	disc []uint32
+
+	// low is the earliest discovered node reachable from each node's subtree by one back edge
+	low    []uint32
+	parent []Node
+
+	// next is the position in edgeTargets of the next neighbor to visit
+	next []int
+
+	// subtree is the weight of each node's subtree
+	subtree []int
+
+	// separated and heaviest are the total and largest weight of the subtrees that removing each node
+	// would cut off
+	separated []int
+	heaviest  []int
+
+	time      uint32
+	stack     []Node
+	component []Node
+}
+
+// search visits every node connected to root and adds the number of subtrees each node separates to
+// pieces. s.component is set to the nodes visited.
+func (s *cutSearch) search(root Node, pieces []uint32) {
+	s.component = s.component[:0]
+	s.discover(root, root)
+	for len(s.stack) > 0 {
+		node := s.stack[len(s.stack)-1]
+		if s.next[node] < s.g.edgeIndex[node+1] {
+			neighbor := s.g.edgeTargets[s.next[node]]
+			s.next[node]++
+			switch {
+			case s.disc[neighbor] == 0:
+				s.discover(neighbor, node)
+			case neighbor != s.parent[node] && s.disc[neighbor] < s.low[node]:
+				s.low[node] = s.disc[neighbor]
+			}
+			continue
+		}
+		s.stack = s.stack[:len(s.stack)-1]
+		s.component = append(s.component, node)
+		if node == root {
+			continue
+		}
+		parent := s.parent[node]
+		s.subtree[parent] += s.subtree[node]
+		if s.low[node] < s.low[parent] {
+			s.low[parent] = s.low[node]
+		}
+		if s.low[node] >= s.disc[parent] {
+			pieces[parent]++
+			s.separated[parent] += s.subtree[node]
+			if s.subtree[node] > s.heaviest[parent] {
+				s.heaviest[parent] = s.subtree[node]
+			}
+		}
+	}
+}
+
+func (s *cutSearch) discover(node, parent Node) {
+	s.time++
+	s.disc[node] = s.time
+	s.low[node] = s.time
+	s.parent[node] = parent
+	s.next[node] = s.g.edgeIndex[node]
+	s.subtree[node] = s.weight(node)
+	s.stack = append(s.stack, node)
+}
@@ -0,0 +1,56 @@
+package graph
+
+// Eccentricity returns the number of edges between node and the nodes farthest from it. Nodes that
+//  can't be reached from node are ignored. Like FindLevels, distances above 254 are reported as 254.
+func (g *Graph) Eccentricity(node Node) int {
+	_, distance := g.FarthestNodes(node, nil)
+	return distance
+}
+
+// FarthestNodes returns the nodes that are farthest from source and their distance in edges. When
+//  include isn't nil, only nodes it returns true for are considered. Nodes that can't be reached from
+//  source are ignored. The nodes are in ascending order.
+func (g *Graph) FarthestNodes(source Node, include func(Node) bool) ([]Node, int) {
+	levels := g.FindLevels(source)
+	return farthestFromLevels(levels, include)
+}
+
+func farthestFromLevels(levels []uint8, include func(Node) bool) ([]Node, int) {
+	var maxLevel uint8
+	var nodes []Node
+	for i, level := range levels {
+		if level == 0 || level < maxLevel {
+			continue
+		}
+		if include != nil && !include(Node(i)) {
+			continue
+		}
+		if level > maxLevel {
+			maxLevel = level
+			nodes = nodes[:0]
+		}
+		nodes = append(nodes, Node(i))
+	}
+	if maxLevel == 0 {
+		return nil, 0
+	}
+	return nodes, int(maxLevel) - 1
+}
+
+// ApproximateDiameter estimates the diameter of the connected component containing start with a double
+//  sweep. It finds the node a farthest from start, then the node b farthest from a, and returns the
+//  distance between them in edges. The result is a lower bound on the component's diameter, and is
+//  usually exact or close to it on real world graphs. When include isn't nil, a and b are limited to the
+//  nodes it returns true for.
+func (g *Graph) ApproximateDiameter(start Node, include func(Node) bool) (distance int, a, b Node) {
+	levels := make([]uint8, len(g.edgeIndex)-1)
+	g.FindLevelsInto(levels, start, 0)
+	nodes, _ := farthestFromLevels(levels, include)
+	if len(nodes) == 0 {
+		return 0, start, start
+	}
+	a = nodes[0]
+	g.FindLevelsInto(levels, a, 0)
+	nodes, distance = farthestFromLevels(levels, include)
+	return distance, a, nodes[0]
+}
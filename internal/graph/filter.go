@@ -0,0 +1,153 @@
+package graph
+
+// PathOption configures a path search
+type PathOption func(*pathOptions)
+
+type pathOptions struct {
+	excluded  []Node
+	filters   []NodeFilter
+	order     *NeighborOrder
+	landmarks *Landmarks
+}
+
+// NodeFilter returns whether node may appear in a path
+type NodeFilter func(node Node) bool
+
+// ExcludeNodes prevents nodes from appearing anywhere in a path. A search from or to an
+// excluded node finds no path.
+func ExcludeNodes(nodes ...Node) PathOption {
+	return func(o *pathOptions) {
+		o.excluded = append(o.excluded, nodes...)
+	}
+}
+
+// FilterNodes only allows nodes that fn returns true for to appear in a path. fn is called for every
+// node the search considers, so it should be cheap.
+func FilterNodes(fn NodeFilter) PathOption {
+	return func(o *pathOptions) {
+		o.filters = append(o.filters, fn)
+	}
+}
+
+// newSearchFilter returns a filter for opts or nil when opts don't change the search. Filters must be
+// released with releaseSearchFilter.
+func (g *Graph) newSearchFilter(opts []PathOption) *searchFilter {
+	if len(opts) == 0 {
+		return nil
+	}
+	var o pathOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.excluded) == 0 && len(o.filters) == 0 && o.order == nil && o.landmarks == nil {
+		return nil
+	}
+	filter := searchFilter{
+		nodeFilters: o.filters,
+		order:       o.order,
+		landmarks:   o.landmarks,
+	}
+	if len(o.excluded) == 0 {
+		return &filter
+	}
+	size := Node(len(g.edgeIndex) - 1)
+	filter.blockedNodes = g.borrowBitset()
+	for _, node := range o.excluded {
+		if node < size {
+			filter.blockedNodes.set(node)
+		}
+	}
+	return &filter
+}
+
+func (g *Graph) releaseSearchFilter(filter *searchFilter) {
+	if filter == nil || filter.blockedNodes == nil {
+		return
+	}
+	g.returnBitset(filter.blockedNodes)
+	filter.blockedNodes = nil
+}
+
+// searchFilter restricts the nodes and edges a path search may use
+type searchFilter struct {
+	// blockedNodes holds nodes that may not be visited
+	blockedNodes *bitset
+
+	// nodeFilters must all return true for a node to be visited
+	nodeFilters []NodeFilter
+
+	// blockedNext holds nodes that may not be adjacent to spur in a path
+	spur        Node
+	blockedNext []Node
+
+	// order replaces the search's PriorityFunc when it isn't nil
+	order *NeighborOrder
+
+	// landmarks bounds the distance between nodes when it isn't nil. The bounds ignore every other
+	// restriction.
+	landmarks *Landmarks
+}
+
+// restricts returns whether f may block any node or edge
+func (f *searchFilter) restricts() bool {
+	return f != nil && (f.blockedNodes != nil || len(f.nodeFilters) > 0 || len(f.blockedNext) > 0)
+}
+
+func (f *searchFilter) neighborOrder() *NeighborOrder {
+	if f == nil {
+		return nil
+	}
+	return f.order
+}
+
+// boundOutcome returns the outcome of a search from a to b when the landmarks alone can tell that
+// no path will be found within maxPathLength nodes
+func (f *searchFilter) boundOutcome(a, b Node, maxPathLength int) (PathOutcome, bool) {
+	if f == nil || f.landmarks == nil {
+		return PathFound, false
+	}
+	lower, _, _ := f.landmarks.DistanceBounds(a, b)
+	if lower+1 > maxPathLength {
+		return MaxPathLengthExceeded, true
+	}
+	return PathFound, false
+}
+
+// blocks returns whether node may not be visited at all
+func (f *searchFilter) blocks(node Node) bool {
+	if f == nil {
+		return false
+	}
+	if f.blockedNodes != nil && f.blockedNodes.contains(node) {
+		return true
+	}
+	for _, fn := range f.nodeFilters {
+		if !fn(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows returns whether the search may step from node to neighbor. Edges are blocked
+// in both directions.
+func (f *searchFilter) allows(node, neighbor Node) bool {
+	if f.blocks(neighbor) {
+		return false
+	}
+	var other Node
+	switch f.spur {
+	case node:
+		other = neighbor
+	case neighbor:
+		other = node
+	default:
+		return true
+	}
+	for _, n := range f.blockedNext {
+		if n == other {
+			return false
+		}
+	}
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"sort"
 	"sync"
+	"sync/atomic"
 )
 
 // Node is a graph node
@@ -21,6 +22,12 @@ type Graph struct {
 	edgeTargets    []Node
 	slicePool      sync.Pool
 	parentsMapPool sync.Pool
+	costPool       sync.Pool
+	levelsPool     sync.Pool
+	bitsetPool     sync.Pool
+
+	// components caches the *Components returned by Components
+	components atomic.Value
 }
 
 // New creates a new Graph
@@ -56,6 +63,24 @@ func (g *Graph) createPools() {
 			return newParentsMap(len(g.edgeIndex) - 1)
 		},
 	}
+	g.costPool = sync.Pool{
+		New: func() interface{} {
+			costs := make([]float64, len(g.edgeIndex)-1)
+			return &costs
+		},
+	}
+	g.levelsPool = sync.Pool{
+		New: func() interface{} {
+			levels := make([]uint8, len(g.edgeIndex)-1)
+			return &levels
+		},
+	}
+	g.bitsetPool = sync.Pool{
+		New: func() interface{} {
+			set := make(bitset, (len(g.edgeIndex)-1+nodeSetBucketMask)>>nodeSetBucketBits)
+			return &set
+		},
+	}
 }
 
 func (g *Graph) borrowParentsMap() *parentsMap {
@@ -67,6 +92,19 @@ func (g *Graph) returnParentsMap(mp *parentsMap) {
 	g.parentsMapPool.Put(mp)
 }
 
+// borrowBitset returns an empty bitset with room for every node. Searches that only track which nodes
+// they've seen use it instead of a parentsMap.
+func (g *Graph) borrowBitset() *bitset {
+	return g.bitsetPool.Get().(*bitset)
+}
+
+func (g *Graph) returnBitset(set *bitset) {
+	for i := range *set {
+		(*set)[i] = 0
+	}
+	g.bitsetPool.Put(set)
+}
+
 func (g *Graph) borrowLevelSlice() *[]Node {
 	s := g.slicePool.Get().(*[]Node)
 	*s = (*s)[:0]
@@ -83,63 +121,79 @@ func (g *Graph) NodeNeighbors(n Node) []Node {
 	return g.edgeTargets[start:end]
 }
 
-// FindLevels returns the hop count of each node in the graph
-func (g *Graph) FindLevels(source Node) []int {
-	size := len(g.edgeIndex) - 1
-	level := make([]int, size)
-	currentLevel := make([]Node, 0, size)
-	nextLevel := make([]Node, 0, size)
-	visited := newParentsMap(size)
-	level[source] = 1
-	currentLevel = append(currentLevel, source)
-	visited.setParent(source, 0)
-
-	levelNumber := 2
-	for len(currentLevel) > 0 {
-		for _, node := range currentLevel {
-			for _, neighbor := range g.NodeNeighbors(node) {
-				if !visited.contains(neighbor) {
-					visited.setParent(neighbor, 0)
-					nextLevel = append(nextLevel, neighbor)
-				}
-			}
-		}
-		sortNodesBYOB(nextLevel, currentLevel[:cap(currentLevel)])
-		for _, neighbor := range nextLevel {
-			level[neighbor] = levelNumber
-		}
-		levelNumber++
-		currentLevel = currentLevel[:0:cap(currentLevel)]
-		currentLevel, nextLevel = nextLevel, currentLevel
-	}
-	return level
-}
-
 // PriorityFunc returns a node's priority when choosing between nodes.  This is not cost.
 //  The shortest path still wins no matter the priority. Higher number is higher priority. 2 gets
 //  chosen before 1.
 type PriorityFunc func(node Node) int64
 
+// defaultMaxPathLength is used when a search is given a maxPathLength of zero or less
+const defaultMaxPathLength = 9
+
+// PathOutcome explains why FindPath did or didn't find a path
+type PathOutcome int8
+
+const (
+	// PathFound means a path was found
+	PathFound PathOutcome = iota
+	// NoPathExists means source and dest are not connected
+	NoPathExists
+	// MaxPathLengthExceeded means the search was abandoned at maxPathLength. A longer path may exist.
+	MaxPathLengthExceeded
+	// InvalidNode means source or dest isn't in the graph
+	InvalidNode
+)
+
+func (o PathOutcome) String() string {
+	switch o {
+	case PathFound:
+		return "path found"
+	case NoPathExists:
+		return "no path exists"
+	case MaxPathLengthExceeded:
+		return "max path length exceeded"
+	case InvalidNode:
+		return "invalid node"
+	default:
+		return "unknown outcome"
+	}
+}
+
 // FindPath finds the shortest path from source to dest
 //  When there are multiple shortest paths, FindPath may return any one of those paths.
 //  The first element of the returned path is always source, and the last element is always dest.
 //  path - is a pointer to a slice that FindPath will set to the found path
-//  When no path is found, path will be set to zero length
-func (g *Graph) FindPath(path *[]Node, maxPathLength int, source, dest Node, priorityFn PriorityFunc) {
-	const defaultMaxPathLength = 9
+//  When no path is found, path will be set to zero length and the returned PathOutcome explains why.
+//  opts can restrict which nodes the path may use.
+//  FindPath never modifies g, so it is safe to call from multiple goroutines.
+func (g *Graph) FindPath(path *[]Node, maxPathLength int, source, dest Node, priorityFn PriorityFunc, opts ...PathOption) PathOutcome {
+	filter := g.newSearchFilter(opts)
+	defer g.releaseSearchFilter(filter)
+	return g.findPath(path, maxPathLength, source, dest, priorityFn, filter)
+}
+
+func (g *Graph) findPath(path *[]Node, maxPathLength int, source, dest Node, priorityFn PriorityFunc, filter *searchFilter) PathOutcome {
 	if maxPathLength <= 0 {
 		maxPathLength = defaultMaxPathLength
 	}
 	size := len(g.edgeIndex) - 1
 	if source >= Node(size) || dest >= Node(size) {
 		setPathLen(path, 0)
-		return
+		return InvalidNode
+	}
+
+	if filter.blocks(source) || filter.blocks(dest) || !g.Components().Connected(source, dest) {
+		setPathLen(path, 0)
+		return NoPathExists
+	}
+	if outcome, ok := filter.boundOutcome(source, dest, maxPathLength); ok {
+		setPathLen(path, 0)
+		return outcome
 	}
 
 	if source == dest {
 		setPathLen(path, 1)
 		(*path)[0] = source
-		return
+		return PathFound
 	}
 
 	srcCurrentLevel := g.borrowLevelSlice()
@@ -148,6 +202,11 @@ func (g *Graph) FindPath(path *[]Node, maxPathLength int, source, dest Node, pri
 	defer g.returnLevelSlice(destCurrentLevel)
 	scratchBuffer := g.borrowLevelSlice()
 	defer g.returnLevelSlice(scratchBuffer)
+	var sortBuffer *[]Node
+	if priorityFn != nil && filter.neighborOrder() == nil {
+		sortBuffer = g.borrowLevelSlice()
+		defer g.returnLevelSlice(sortBuffer)
+	}
 
 	srcParentsMap := g.borrowParentsMap()
 	defer g.returnParentsMap(srcParentsMap)
@@ -156,19 +215,23 @@ func (g *Graph) FindPath(path *[]Node, maxPathLength int, source, dest Node, pri
 
 	*srcCurrentLevel = append(*srcCurrentLevel, source)
 	*destCurrentLevel = append(*destCurrentLevel, dest)
+	// Marking dest as visited lets the source side stop as soon as it reaches dest. Without it, a graph
+	// with odd cycles can find a path through a neighbor source and dest share instead of the edge
+	// between them. The source side always searches first, so source doesn't need the same mark.
+	destParentsMap.setParent(dest, dest)
 	var midPoint Node
 	midFound := false
 	srcPathLen := 1
 	destPathLen := 1
 	midFoundBySource := false
 	for len(*srcCurrentLevel) > 0 && len(*destCurrentLevel) > 0 {
-		midPoint, midFound = g.nextLevel(srcCurrentLevel, scratchBuffer, srcParentsMap, destParentsMap, priorityFn)
+		midPoint, midFound = g.nextLevel(srcCurrentLevel, scratchBuffer, sortBuffer, srcParentsMap, destParentsMap, priorityFn, filter)
 		if midFound || srcPathLen+destPathLen >= maxPathLength {
 			midFoundBySource = true
 			break
 		}
 		srcPathLen++
-		midPoint, midFound = g.nextLevel(destCurrentLevel, scratchBuffer, destParentsMap, srcParentsMap, priorityFn)
+		midPoint, midFound = g.nextLevel(destCurrentLevel, scratchBuffer, sortBuffer, destParentsMap, srcParentsMap, priorityFn, filter)
 
 		if midFound || srcPathLen+destPathLen >= maxPathLength {
 			break
@@ -177,13 +240,16 @@ func (g *Graph) FindPath(path *[]Node, maxPathLength int, source, dest Node, pri
 	}
 	if !midFound {
 		*path = (*path)[:0]
-		return
+		if len(*srcCurrentLevel) == 0 || len(*destCurrentLevel) == 0 {
+			return NoPathExists
+		}
+		return MaxPathLengthExceeded
 	}
 	if midPoint == source {
 		setPathLen(path, 2)
 		(*path)[0] = source
 		(*path)[1] = dest
-		return
+		return PathFound
 	}
 
 	setPathLen(path, srcPathLen+destPathLen)
@@ -204,6 +270,7 @@ func (g *Graph) FindPath(path *[]Node, maxPathLength int, source, dest Node, pri
 		pathIdx++
 		(*path)[pathIdx] = n
 	}
+	return PathFound
 }
 
 func setPathLen(p *[]Node, length int) {
@@ -216,20 +283,35 @@ func setPathLen(p *[]Node, length int) {
 	*p = append(*p, make([]Node, extra)...)
 }
 
-func (g *Graph) nextLevel(currentLevel, scratchBuffer *[]Node, parents, otherParents *parentsMap, priority PriorityFunc) (Node, bool) {
+// nextLevel replaces currentLevel with its unvisited neighbors and reports the first neighbor that
+// otherParents has already visited. sortBuffer holds a copy of each node's neighbors when priority
+// is used so that the graph's own adjacency is never reordered.
+func (g *Graph) nextLevel(currentLevel, scratchBuffer, sortBuffer *[]Node, parents, otherParents *parentsMap, priority PriorityFunc, filter *searchFilter) (Node, bool) {
 	*scratchBuffer = (*scratchBuffer)[:0]
 	var midPoint Node
 	foundMid := false
+	filtering := filter.restricts()
+	order := filter.neighborOrder()
 	levelLen := len(*currentLevel)
 	for i := 0; i < levelLen && !foundMid; i++ {
 		node := (*currentLevel)[i]
-		neighbors := g.NodeNeighbors(node)
-		if priority != nil {
-			prioritySort(&neighbors, priority)
+		var neighbors []Node
+		if order != nil {
+			neighbors = order.NodeNeighbors(node)
+		} else {
+			neighbors = g.NodeNeighbors(node)
+			if priority != nil {
+				*sortBuffer = append((*sortBuffer)[:0], neighbors...)
+				neighbors = *sortBuffer
+				prioritySort(&neighbors, priority)
+			}
 		}
 		nLen := len(neighbors)
 		for j := 0; j < nLen && !foundMid; j++ {
 			neighbor := neighbors[j]
+			if filtering && !filter.allows(node, neighbor) {
+				continue
+			}
 			if !parents.contains(neighbor) {
 				parents.setParent(neighbor, node)
 				*scratchBuffer = append(*scratchBuffer, neighbor)
@@ -245,7 +327,7 @@ func (g *Graph) nextLevel(currentLevel, scratchBuffer *[]Node, parents, otherPar
 }
 
 func prioritySort(nodes *[]Node, fn PriorityFunc) {
-	sort.Slice(*nodes, func(i, j int) bool {
+	sort.SliceStable(*nodes, func(i, j int) bool {
 		return fn((*nodes)[i]) > fn((*nodes)[j])
 	})
 }
@@ -1,9 +1,13 @@
 package graph
 
 import (
+	"bytes"
 	"encoding/gob"
+	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -100,6 +104,807 @@ func TestGraph_FindPath(t *testing.T) {
 			return 0
 		})
 		require.Equal(t, []Node{0, 1, 2, 4, 5, 6, 7}, path)
+		require.Equal(t, []Node{1, 3, 4}, g.NodeNeighbors(2))
+	})
+
+	t.Run("neighbor order", func(t *testing.T) {
+		neighbors := [][]Node{
+			0: {1},
+			1: {0, 2},
+			2: {1, 3, 4},
+			3: {2, 5},
+			4: {2, 5},
+			5: {3, 4, 6},
+			6: {5, 7},
+			7: {6},
+		}
+		g := New(neighbors)
+		order := g.OrderNeighbors(func(node Node) int64 {
+			if node == 4 {
+				return 1
+			}
+			return 0
+		})
+		require.Equal(t, []Node{4, 1, 3}, order.NodeNeighbors(2))
+		require.Equal(t, []Node{1, 3, 4}, g.NodeNeighbors(2))
+		var path []Node
+		g.FindPath(&path, 0, 0, 7, nil, WithNeighborOrder(order))
+		require.Equal(t, []Node{0, 1, 2, 4, 5, 6, 7}, path)
+	})
+}
+
+func TestGraph_FindPath_oddCycles(t *testing.T) {
+	triangle := New([][]Node{
+		0: {1, 2},
+		1: {0, 2},
+		2: {0, 1},
+	})
+	var path []Node
+	require.Equal(t, PathFound, triangle.FindPath(&path, 0, 0, 2, nil))
+	require.Equal(t, []Node{0, 2}, path)
+	require.Equal(t, PathFound, triangle.FindPath(&path, 0, 2, 0, nil))
+	require.Equal(t, []Node{2, 0}, path)
+
+	const size = 200
+	rnd := rand.New(rand.NewSource(1))
+	neighbors := make([][]Node, size)
+	for i := 0; i < size*2; i++ {
+		a, b := Node(rnd.Intn(size)), Node(rnd.Intn(size))
+		if a != b {
+			neighbors[a] = append(neighbors[a], b)
+			neighbors[b] = append(neighbors[b], a)
+		}
+	}
+	g := New(neighbors)
+	for source := Node(0); source < size; source += 7 {
+		levels := g.FindLevels(source)
+		for dest := Node(0); dest < size; dest++ {
+			if levels[dest] == 0 {
+				continue
+			}
+			require.Equal(t, PathFound, g.FindPath(&path, 999, source, dest, nil))
+			require.Len(t, path, int(levels[dest]), "%d to %d", source, dest)
+		}
+	}
+}
+
+func TestGraph_FindPath_concurrent(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1},
+		1: {0, 2},
+		2: {1, 3, 4},
+		3: {2, 5},
+		4: {2, 5},
+		5: {3, 4, 6},
+		6: {5, 7},
+		7: {6},
+	}
+	g := New(neighbors)
+	prefer := func(preferred Node) PriorityFunc {
+		return func(node Node) int64 {
+			if node == preferred {
+				return 1
+			}
+			return 0
+		}
+	}
+	want := map[Node][]Node{
+		3: {0, 1, 2, 3, 5, 6, 7},
+		4: {0, 1, 2, 4, 5, 6, 7},
+	}
+	var wg sync.WaitGroup
+	errs := make(chan string, 16)
+	for i := 0; i < 16; i++ {
+		preferred := Node(3 + i%2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				var path []Node
+				g.FindPath(&path, 0, 0, 7, prefer(preferred))
+				if !nodesEqual(path, want[preferred]) {
+					errs <- fmt.Sprintf("preferring %d got %v", preferred, path)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for msg := range errs {
+		t.Error(msg)
+	}
+	require.Equal(t, []Node{1, 3, 4}, g.NodeNeighbors(2))
+}
+
+func TestGraph_FindPath_outcome(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1},
+		1: {0, 2},
+		2: {1, 3},
+		3: {2, 4},
+		4: {3},
+		5: {6},
+		6: {5},
+	}
+	g := New(neighbors)
+	for _, td := range []struct {
+		src, dest     Node
+		maxPathLength int
+		want          PathOutcome
+		wantLen       int
+	}{
+		{src: 0, dest: 4, maxPathLength: 5, want: PathFound, wantLen: 5},
+		{src: 0, dest: 4, maxPathLength: 4, want: MaxPathLengthExceeded},
+		{src: 0, dest: 5, maxPathLength: 99, want: NoPathExists},
+		{src: 5, dest: 0, maxPathLength: 99, want: NoPathExists},
+		{src: 0, dest: 7, maxPathLength: 99, want: InvalidNode},
+		{src: 3, dest: 3, maxPathLength: 99, want: PathFound, wantLen: 1},
+	} {
+		path := []Node{}
+		got := g.FindPath(&path, td.maxPathLength, td.src, td.dest, nil)
+		require.Equal(t, td.want, got, "%d -> %d", td.src, td.dest)
+		require.Len(t, path, td.wantLen, "%d -> %d", td.src, td.dest)
+	}
+}
+
+func TestGraph_FindPath_excludeNodes(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1, 3},
+		1: {0, 2, 6},
+		2: {1, 5},
+		3: {0, 4},
+		4: {3, 5},
+		5: {2, 4, 7},
+		6: {1, 7},
+		7: {5, 6},
+	}
+	g := New(neighbors)
+	var path []Node
+	outcome := g.FindPath(&path, 0, 0, 5, nil, ExcludeNodes(2))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, []Node{0, 3, 4, 5}, path)
+
+	outcome = g.FindPath(&path, 0, 0, 5, nil, ExcludeNodes(2), ExcludeNodes(4))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, []Node{0, 1, 6, 7, 5}, path)
+
+	outcome = g.FindPath(&path, 0, 0, 5, nil, ExcludeNodes(1, 4))
+	require.Equal(t, NoPathExists, outcome)
+	require.Empty(t, path)
+
+	outcome = g.FindPath(&path, 0, 0, 5, nil, ExcludeNodes(5))
+	require.Equal(t, NoPathExists, outcome)
+
+	paths, outcome := g.KShortestPaths(5, 0, 0, 5, nil, ExcludeNodes(3))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, [][]Node{{0, 1, 2, 5}, {0, 1, 6, 7, 5}}, paths)
+
+	paths, count, outcome := g.AllShortestPaths(0, 0, 5, 5, ExcludeNodes(3))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, uint64(1), count)
+	require.Equal(t, [][]Node{{0, 1, 2, 5}}, paths)
+
+	outcome = g.FindPath(&path, 0, 0, 5, nil, FilterNodes(func(node Node) bool {
+		return node != 1
+	}))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, []Node{0, 3, 4, 5}, path)
+
+	paths, outcome = g.KShortestPaths(5, 0, 0, 5, nil, FilterNodes(func(node Node) bool {
+		return node != 2
+	}))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, [][]Node{{0, 3, 4, 5}, {0, 1, 6, 7, 5}}, paths)
+
+	// excluded nodes don't leak into later searches
+	outcome = g.FindPath(&path, 0, 0, 5, nil)
+	require.Equal(t, PathFound, outcome)
+	require.Len(t, path, 4)
+}
+
+func TestGraph_AllShortestPaths(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1, 2},
+		1: {0, 3},
+		2: {0, 3},
+		3: {1, 2, 4, 5},
+		4: {3, 6},
+		5: {3, 6},
+		6: {4, 5, 7},
+		7: {6},
+		8: {},
+	}
+	g := New(neighbors)
+	paths, count, outcome := g.AllShortestPaths(0, 0, 6, 10)
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, uint64(4), count)
+	require.Equal(t, [][]Node{
+		{0, 1, 3, 4, 6},
+		{0, 2, 3, 4, 6},
+		{0, 1, 3, 5, 6},
+		{0, 2, 3, 5, 6},
+	}, paths)
+
+	paths, count, outcome = g.AllShortestPaths(0, 7, 0, 3)
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, uint64(4), count)
+	require.Len(t, paths, 3)
+	for _, path := range paths {
+		require.Len(t, path, 6)
+		require.Equal(t, Node(7), path[0])
+		require.Equal(t, Node(0), path[5])
+	}
+
+	_, _, outcome = g.AllShortestPaths(4, 0, 6, 10)
+	require.Equal(t, MaxPathLengthExceeded, outcome)
+	_, count, outcome = g.AllShortestPaths(0, 0, 8, 10)
+	require.Equal(t, NoPathExists, outcome)
+	require.Zero(t, count)
+	paths, count, outcome = g.AllShortestPaths(0, 2, 2, 10)
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, uint64(1), count)
+	require.Equal(t, [][]Node{{2}}, paths)
+}
+
+func TestGraph_KShortestPaths(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1, 3},
+		1: {0, 2, 6},
+		2: {1, 5},
+		3: {0, 4},
+		4: {3, 5},
+		5: {2, 4, 7},
+		6: {1, 7},
+		7: {5, 6},
+		8: {},
+	}
+	g := New(neighbors)
+	paths, outcome := g.KShortestPaths(10, 0, 0, 5, nil)
+	require.Equal(t, PathFound, outcome)
+	require.Len(t, paths, 3)
+	var first []Node
+	g.FindPath(&first, 0, 0, 5, nil)
+	require.Equal(t, first, paths[0])
+	require.ElementsMatch(t, [][]Node{{0, 1, 2, 5}, {0, 3, 4, 5}}, paths[:2])
+	require.Equal(t, []Node{0, 1, 6, 7, 5}, paths[2])
+
+	paths, outcome = g.KShortestPaths(2, 0, 0, 5, nil)
+	require.Equal(t, PathFound, outcome)
+	require.Len(t, paths, 2)
+
+	paths, outcome = g.KShortestPaths(10, 4, 0, 5, nil)
+	require.Equal(t, PathFound, outcome)
+	require.Len(t, paths, 2)
+
+	paths, outcome = g.KShortestPaths(3, 0, 0, 8, nil)
+	require.Equal(t, NoPathExists, outcome)
+	require.Empty(t, paths)
+}
+
+func TestGraph_WeightedPath(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1, 3},
+		1: {0, 5},
+		2: {},
+		3: {0, 4},
+		4: {3, 5},
+		5: {1, 4},
+	}
+	g := New(neighbors)
+	weights := map[Node]float64{1: 10, 3: 1, 4: 2}
+	cost := func(node, neighbor Node) float64 {
+		return weights[neighbor]
+	}
+	var path []Node
+	total, outcome := g.WeightedPath(&path, 0, 5, cost)
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, []Node{0, 3, 4, 5}, path)
+	require.Equal(t, float64(3), total)
+
+	var hops []Node
+	g.FindPath(&hops, 0, 0, 5, nil)
+	require.Equal(t, []Node{0, 1, 5}, hops)
+
+	total, outcome = g.WeightedPath(&path, 0, 5, cost, ExcludeNodes(4))
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, []Node{0, 1, 5}, path)
+	require.Equal(t, float64(10), total)
+
+	_, outcome = g.WeightedPath(&path, 0, 2, cost)
+	require.Equal(t, NoPathExists, outcome)
+	require.Empty(t, path)
+	_, outcome = g.WeightedPath(&path, 0, 99, cost)
+	require.Equal(t, InvalidNode, outcome)
+	total, outcome = g.WeightedPath(&path, 2, 2, cost)
+	require.Equal(t, PathFound, outcome)
+	require.Equal(t, []Node{2}, path)
+	require.Zero(t, total)
+}
+
+func TestGraph_BuildLandmarks(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1},
+		1: {0, 2, 3},
+		2: {1, 4},
+		3: {1, 4},
+		4: {2, 3, 5},
+		5: {4},
+		6: {7},
+		7: {6},
+	}
+	g := New(neighbors)
+	landmarks := g.BuildLandmarks(2)
+	require.Equal(t, []Node{1, 5}, landmarks.Nodes())
+
+	lower, upper, connected := landmarks.DistanceBounds(0, 5)
+	require.True(t, connected)
+	require.Equal(t, 4, lower)
+	require.Equal(t, 4, upper)
+	_, _, connected = landmarks.DistanceBounds(0, 6)
+	require.False(t, connected)
+	lower, upper, connected = landmarks.DistanceBounds(6, 7)
+	require.True(t, connected)
+	require.Equal(t, 0, lower)
+	require.Equal(t, -1, upper)
+	distance, ok := landmarks.Distance(0, 5)
+	require.True(t, ok)
+	require.Equal(t, 4, distance)
+	_, ok = landmarks.Distance(2, 3)
+	require.False(t, ok)
+	_, ok = landmarks.Distance(0, 6)
+	require.False(t, ok)
+
+	// landmarks end hopeless searches early but don't change which path is found
+	var path, want []Node
+	require.Equal(t, MaxPathLengthExceeded, g.FindPath(&path, 4, 0, 5, nil, WithLandmarks(landmarks)))
+	require.Empty(t, path)
+	priority := func(node Node) int64 {
+		return int64(node)
+	}
+	order := WithNeighborOrder(g.OrderNeighbors(priority))
+	for _, opts := range [][]PathOption{nil, {order}} {
+		require.Equal(t, PathFound, g.FindPath(&want, 0, 0, 5, priority, opts...))
+		require.Equal(t, PathFound, g.FindPath(&path, 0, 0, 5, priority, append(opts, WithLandmarks(landmarks))...))
+		require.Equal(t, want, path)
+	}
+
+	require.True(t, landmarks.Matches(g))
+	neighbors[5] = []Node{}
+	neighbors[4] = []Node{2, 3}
+	require.False(t, landmarks.Matches(New(neighbors)))
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(landmarks))
+	var decoded Landmarks
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	require.Equal(t, landmarks, &decoded)
+}
+
+func TestGraph_FindPath_landmarks(t *testing.T) {
+	const size = 2000
+	rnd := rand.New(rand.NewSource(1))
+	sets := make([]map[Node]bool, size)
+	for i := range sets {
+		sets[i] = map[Node]bool{}
+	}
+	// the last 100 nodes are a separate component
+	for i := 0; i < size*2; i++ {
+		a, b := Node(rnd.Intn(size-100)), Node(rnd.Intn(size-100))
+		if i%20 == 0 {
+			a, b = Node(size-100+rnd.Intn(100)), Node(size-100+rnd.Intn(100))
+		}
+		if a != b {
+			sets[a][b] = true
+			sets[b][a] = true
+		}
+	}
+	neighbors := make([][]Node, size)
+	for i, set := range sets {
+		for n := range set {
+			neighbors[i] = append(neighbors[i], n)
+		}
+		sortNodesBYOB(neighbors[i], make([]Node, len(neighbors[i])))
+	}
+	g := New(neighbors)
+	opt := WithLandmarks(g.BuildLandmarks(8))
+	order := WithNeighborOrder(g.OrderNeighbors(func(node Node) int64 {
+		return int64(node % 7)
+	}))
+	var want, got []Node
+	for i := 0; i < 2000; i++ {
+		src, dest := Node(rnd.Intn(size)), Node(rnd.Intn(size))
+		maxPathLength := 2 + rnd.Intn(12)
+		for _, opts := range [][]PathOption{nil, {order}} {
+			wantOutcome := g.FindPath(&want, maxPathLength, src, dest, nil, opts...)
+			gotOutcome := g.FindPath(&got, maxPathLength, src, dest, nil, append(opts, opt)...)
+			require.Equal(t, wantOutcome, gotOutcome, "%d to %d", src, dest)
+			require.Equal(t, want, got, "%d to %d", src, dest)
+		}
+	}
+}
+
+func TestGraph_Components(t *testing.T) {
+	neighbors := [][]Node{
+		0: {5},
+		1: {2},
+		2: {1, 3},
+		3: {2},
+		4: {},
+		5: {0},
+	}
+	g := New(neighbors)
+	components := g.Components()
+	require.Same(t, components, g.Components())
+	require.Equal(t, 3, components.Count())
+	require.Equal(t, 0, components.Component(2))
+	require.Equal(t, 1, components.Component(5))
+	require.Equal(t, 2, components.Component(4))
+	require.Equal(t, []int{3, 2, 1}, []int{components.Size(0), components.Size(1), components.Size(2)})
+	require.True(t, components.Connected(0, 5))
+	require.False(t, components.Connected(0, 1))
+	require.Equal(t, []Node{1, 2, 3}, g.LargestComponent())
+	require.Empty(t, New(nil).LargestComponent())
+
+	var path []Node
+	require.Equal(t, NoPathExists, g.FindPath(&path, 0, 0, 3, nil))
+	_, _, outcome := g.AllShortestPaths(0, 0, 3, 1)
+	require.Equal(t, NoPathExists, outcome)
+}
+
+func TestGraph_FindLevels(t *testing.T) {
+	t.Run("", func(t *testing.T) {
+		neighbors := [][]Node{
+			0: {1},
+			1: {0, 2, 3},
+			2: {1, 4},
+			3: {1, 4},
+			4: {2, 3},
+			5: {},
+		}
+		g := New(neighbors)
+		require.Equal(t, []uint8{2, 1, 2, 2, 3, 0}, g.FindLevels(1))
+
+		levels := g.BorrowLevels()
+		g.FindLevelsInto(*levels, 0, 2)
+		require.Equal(t, []uint8{1, 2, 3, 3, 4, 0}, *levels)
+		g.ReturnLevels(levels)
+		levels = g.BorrowLevels()
+		g.FindLevelsInto(*levels, 1, 2)
+		require.Equal(t, []uint8{2, 1, 2, 2, 3, 0}, *levels)
+		g.ReturnLevels(levels)
+	})
+
+	t.Run("saturates", func(t *testing.T) {
+		neighbors := make([][]Node, 300)
+		for i := range neighbors {
+			if i > 0 {
+				neighbors[i] = append(neighbors[i], Node(i-1))
+			}
+			if i < len(neighbors)-1 {
+				neighbors[i] = append(neighbors[i], Node(i+1))
+			}
+		}
+		levels := New(neighbors).FindLevels(0)
+		require.Equal(t, uint8(254), levels[253])
+		require.Equal(t, uint8(255), levels[254])
+		require.Equal(t, uint8(255), levels[299])
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		const size = 50_000
+		rnd := rand.New(rand.NewSource(1))
+		neighbors := make([][]Node, size)
+		for i := 0; i < size*3; i++ {
+			a, b := Node(rnd.Intn(size)), Node(rnd.Intn(size))
+			if a != b {
+				neighbors[a] = append(neighbors[a], b)
+				neighbors[b] = append(neighbors[b], a)
+			}
+		}
+		g := New(neighbors)
+		for _, source := range []Node{0, 1234, 49_999} {
+			want := make([]uint8, size)
+			want[source] = 1
+			queue := []Node{source}
+			for len(queue) > 0 {
+				node := queue[0]
+				queue = queue[1:]
+				for _, neighbor := range neighbors[node] {
+					if want[neighbor] == 0 {
+						want[neighbor] = want[node] + 1
+						queue = append(queue, neighbor)
+					}
+				}
+			}
+			require.Equal(t, want, g.FindLevels(source))
+			got := make([]uint8, size)
+			for _, workers := range []int{0, 3, 8} {
+				g.FindLevelsInto(got, source, workers)
+				require.Equal(t, want, got)
+			}
+		}
+	})
+}
+
+func TestGraph_FarthestNodes(t *testing.T) {
+	neighbors := [][]Node{
+		0: {1},
+		1: {0, 2, 3},
+		2: {1, 4},
+		3: {1, 5},
+		4: {2},
+		5: {3, 6},
+		6: {5},
+		7: {},
+	}
+	g := New(neighbors)
+	nodes, distance := g.FarthestNodes(0, nil)
+	require.Equal(t, []Node{6}, nodes)
+	require.Equal(t, 4, distance)
+	nodes, distance = g.FarthestNodes(0, func(node Node) bool { return node%2 == 1 })
+	require.Equal(t, []Node{5}, nodes)
+	require.Equal(t, 3, distance)
+	nodes, distance = g.FarthestNodes(3, func(node Node) bool { return node != 4 })
+	require.Equal(t, []Node{0, 2, 6}, nodes)
+	require.Equal(t, 2, distance)
+	nodes, distance = g.FarthestNodes(7, nil)
+	require.Equal(t, []Node{7}, nodes)
+	require.Equal(t, 0, distance)
+
+	require.Equal(t, 4, g.Eccentricity(0))
+	require.Equal(t, 3, g.Eccentricity(1))
+
+	distance, a, b := g.ApproximateDiameter(1, nil)
+	require.Equal(t, 5, distance)
+	require.Equal(t, Node(6), a)
+	require.Equal(t, Node(4), b)
+}
+
+func TestGraph_Betweenness(t *testing.T) {
+	allNodes := func(g *Graph) []Node {
+		nodes := make([]Node, len(g.edgeIndex)-1)
+		for i := range nodes {
+			nodes[i] = Node(i)
+		}
+		return nodes
+	}
+
+	t.Run("path", func(t *testing.T) {
+		g := New([][]Node{
+			0: {1},
+			1: {0, 2},
+			2: {1, 3},
+			3: {2},
+			4: {},
+		})
+		require.Equal(t, []float64{0, 2, 2, 0, 0}, g.Betweenness(allNodes(g), 1))
+	})
+
+	t.Run("diamond", func(t *testing.T) {
+		g := New([][]Node{
+			0: {1, 2},
+			1: {0, 3},
+			2: {0, 3},
+			3: {1, 2},
+		})
+		require.Equal(t, []float64{0.5, 0.5, 0.5, 0.5}, g.Betweenness(allNodes(g), 2))
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		const size = 500
+		rnd := rand.New(rand.NewSource(1))
+		neighbors := make([][]Node, size)
+		for i := 0; i < size*2; i++ {
+			a, b := Node(rnd.Intn(size)), Node(rnd.Intn(size))
+			if a != b {
+				neighbors[a] = append(neighbors[a], b)
+				neighbors[b] = append(neighbors[b], a)
+			}
+		}
+		g := New(neighbors)
+		sources := allNodes(g)
+		exact := g.Betweenness(sources, 1)
+		require.Equal(t, exact, g.Betweenness(sources, 4))
+
+		rnd.Shuffle(len(sources), func(i, j int) {
+			sources[i], sources[j] = sources[j], sources[i]
+		})
+		// individual estimates are noisy, but the total is stable
+		sampled := g.Betweenness(sources[:size/4], 0)
+		var exactTotal, sampledTotal float64
+		for i := range exact {
+			exactTotal += exact[i]
+			sampledTotal += sampled[i]
+		}
+		require.InEpsilon(t, exactTotal, sampledTotal, 0.05)
+	})
+}
+
+func TestGraph_PageRank(t *testing.T) {
+	cycle := New([][]Node{
+		0: {1, 3},
+		1: {0, 2},
+		2: {1, 3},
+		3: {2, 0},
+	})
+	ranks, _ := cycle.PageRank(nil)
+	require.InDeltaSlice(t, []float64{0.25, 0.25, 0.25, 0.25}, ranks, 1e-9)
+
+	star := New([][]Node{
+		0: {1, 2, 3},
+		1: {0},
+		2: {0},
+		3: {0},
+		4: {},
+	})
+	ranks, iterations := star.PageRank(&PageRankOptions{Damping: 0.5})
+	require.Less(t, iterations, 100)
+	var total float64
+	for _, rank := range ranks {
+		total += rank
+	}
+	require.InDelta(t, 1, total, 1e-6)
+	require.Greater(t, ranks[0], ranks[1])
+	require.InDelta(t, ranks[1], ranks[3], 1e-9)
+	require.Greater(t, ranks[1], ranks[4])
+
+	ranks, iterations = star.PageRank(&PageRankOptions{MaxIterations: 2})
+	require.Equal(t, 2, iterations)
+	require.Len(t, ranks, 5)
+}
+
+func TestGraph_PersonalizedPageRank(t *testing.T) {
+	const size = 300
+	const damping = 0.85
+	rnd := rand.New(rand.NewSource(1))
+	neighbors := make([][]Node, size)
+	for i := 0; i < size*2; i++ {
+		a, b := Node(rnd.Intn(size)), Node(rnd.Intn(size))
+		if a != b {
+			neighbors[a] = append(neighbors[a], b)
+			neighbors[b] = append(neighbors[b], a)
+		}
+	}
+	g := New(neighbors)
+	for _, source := range []Node{0, 17, 250} {
+		// power iteration that always jumps back to source
+		want := make([]float64, size)
+		want[source] = 1
+		for i := 0; i < 200; i++ {
+			next := make([]float64, size)
+			next[source] = 1 - damping
+			for n, rank := range want {
+				if len(neighbors[n]) == 0 {
+					next[source] += damping * rank
+					continue
+				}
+				for _, neighbor := range neighbors[n] {
+					next[neighbor] += damping * rank / float64(len(neighbors[n]))
+				}
+			}
+			want = next
+		}
+		got := g.PersonalizedPageRank(source, &PageRankOptions{Damping: damping, Tolerance: 1e-9})
+		for n := range want {
+			require.InDelta(t, want[n], got[Node(n)], 1e-6)
+		}
+		coarse := g.PersonalizedPageRank(source, &PageRankOptions{Tolerance: 1e-3})
+		require.Less(t, len(coarse), len(got))
+	}
+}
+
+func TestGraph_Project(t *testing.T) {
+	// 0 through 3 are cast members and 4 through 6 are movies
+	g := New([][]Node{
+		0: {4, 5},
+		1: {4, 5},
+		2: {5, 6},
+		3: {},
+		4: {0, 1},
+		5: {0, 1, 2},
+		6: {2},
+	})
+	isCast := func(node Node) bool { return node < 4 }
+	p := g.Project(isCast)
+	require.Equal(t, []Node{1, 2}, p.NodeNeighbors(0))
+	require.Equal(t, []uint32{2, 1}, p.NeighborWeights(0))
+	require.Equal(t, len(p.edgeTargets), cap(p.edgeTargets))
+	require.Equal(t, []Node{0, 1}, p.NodeNeighbors(2))
+	require.Empty(t, p.NodeNeighbors(3))
+	require.Empty(t, p.NodeNeighbors(5))
+	require.Equal(t, 2, p.Weight(1, 0))
+	require.Equal(t, 1, p.Weight(1, 2))
+	require.Equal(t, 0, p.Weight(0, 3))
+	require.Equal(t, 0, p.Weight(0, 5))
+
+	var path []Node
+	require.Equal(t, PathFound, p.FindPath(&path, 0, 0, 2, nil))
+	require.Equal(t, []Node{0, 2}, path)
+	require.Equal(t, []uint8{1, 2, 2, 0, 0, 0, 0}, p.FindLevels(0))
+}
+
+func TestGraph_FindCuts(t *testing.T) {
+	t.Run("", func(t *testing.T) {
+		// 0 joins the triangle 1, 2, 3 to the path 4, 5. 6 is alone.
+		g := New([][]Node{
+			0: {1, 4},
+			1: {0, 2, 3},
+			2: {1, 3},
+			3: {1, 2},
+			4: {0, 5},
+			5: {4},
+			6: {},
+		})
+		cuts := g.FindCuts(nil)
+		require.True(t, cuts.IsArticulation(0))
+		require.Equal(t, 2, cuts.Pieces(0))
+		require.Equal(t, 2, cuts.Stranded(0))
+		require.True(t, cuts.IsArticulation(1))
+		require.Equal(t, 2, cuts.Pieces(1))
+		require.Equal(t, 2, cuts.Stranded(1))
+		require.False(t, cuts.IsArticulation(2))
+		require.Equal(t, 1, cuts.Pieces(2))
+		require.Equal(t, 0, cuts.Stranded(2))
+		require.True(t, cuts.IsArticulation(4))
+		require.Equal(t, 1, cuts.Stranded(4))
+		require.False(t, cuts.IsArticulation(5))
+		require.Equal(t, 0, cuts.Pieces(6))
+
+		// only even nodes count
+		cuts = g.FindCuts(func(node Node) int { return 1 - int(node%2) })
+		require.Equal(t, 1, cuts.Stranded(0))
+		require.Equal(t, 0, cuts.Stranded(4))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		const size = 300
+		rnd := rand.New(rand.NewSource(1))
+		neighbors := make([][]Node, size)
+		for i := 0; i < size; i++ {
+			a, b := Node(rnd.Intn(size)), Node(rnd.Intn(size))
+			if a != b {
+				neighbors[a] = append(neighbors[a], b)
+				neighbors[b] = append(neighbors[b], a)
+			}
+		}
+		g := New(neighbors)
+		weight := func(node Node) int { return int(node % 3) }
+		cuts := g.FindCuts(weight)
+		components := g.Components()
+		for removed := Node(0); removed < size; removed++ {
+			// label the pieces of removed's component by searching around it
+			piece := make([]int, size)
+			var weights []int
+			for start := Node(0); start < size; start++ {
+				if start == removed || piece[start] != 0 || !components.Connected(start, removed) {
+					continue
+				}
+				weights = append(weights, 0)
+				piece[start] = len(weights)
+				queue := []Node{start}
+				for len(queue) > 0 {
+					node := queue[0]
+					queue = queue[1:]
+					weights[len(weights)-1] += weight(node)
+					for _, neighbor := range neighbors[node] {
+						if neighbor != removed && piece[neighbor] == 0 {
+							piece[neighbor] = len(weights)
+							queue = append(queue, neighbor)
+						}
+					}
+				}
+			}
+			total, heaviest := 0, 0
+			for _, w := range weights {
+				total += w
+				if w > heaviest {
+					heaviest = w
+				}
+			}
+			require.Equal(t, len(weights), cuts.Pieces(removed), "node %d", removed)
+			require.Equal(t, total-heaviest, cuts.Stranded(removed), "node %d", removed)
+		}
 	})
 }
 
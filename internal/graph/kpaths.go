@@ -0,0 +1,98 @@
+package graph
+
+// KShortestPaths finds up to k shortest loopless paths from source to dest using Yen's algorithm.
+//  Paths are returned shortest first. The first path is the one FindPath would return. Paths of equal
+//  length are returned in the order they were found. When no path is found the returned PathOutcome
+//  explains why. opts can restrict which nodes the paths may use.
+func (g *Graph) KShortestPaths(k, maxPathLength int, source, dest Node, priorityFn PriorityFunc, opts ...PathOption) ([][]Node, PathOutcome) {
+	filter := g.newSearchFilter(opts)
+	if filter == nil {
+		filter = &searchFilter{}
+	}
+	if filter.blockedNodes == nil {
+		filter.blockedNodes = g.borrowBitset()
+	}
+	defer g.releaseSearchFilter(filter)
+	blocked := *filter.blockedNodes
+
+	var first []Node
+	outcome := g.findPath(&first, maxPathLength, source, dest, priorityFn, filter)
+	if outcome != PathFound || k < 1 {
+		return nil, outcome
+	}
+	if maxPathLength <= 0 {
+		maxPathLength = defaultMaxPathLength
+	}
+	found := [][]Node{first}
+	var candidates [][]Node
+
+	spurPath := g.borrowLevelSlice()
+	defer g.returnLevelSlice(spurPath)
+
+	for len(found) < k {
+		prev := found[len(found)-1]
+		for i := 0; i < len(prev)-1 && maxPathLength-i >= 2; i++ {
+			root := prev[:i+1]
+			filter.spur = prev[i]
+			filter.blockedNext = filter.blockedNext[:0]
+			for _, p := range found {
+				if len(p) > i+1 && nodesEqual(p[:i+1], root) {
+					filter.blockedNext = append(filter.blockedNext, p[i+1])
+				}
+			}
+			for _, n := range root[:i] {
+				blocked.set(n)
+			}
+			if g.findPath(spurPath, maxPathLength-i, filter.spur, dest, priorityFn, filter) == PathFound {
+				candidate := make([]Node, 0, i+len(*spurPath))
+				candidate = append(candidate, root[:i]...)
+				candidate = append(candidate, *spurPath...)
+				if !containsPath(found, candidate) && !containsPath(candidates, candidate) {
+					candidates = insertPath(candidates, candidate)
+				}
+			}
+			for _, n := range root[:i] {
+				blocked.unset(n)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		found = append(found, candidates[0])
+		candidates = candidates[1:]
+	}
+	return found, PathFound
+}
+
+// insertPath inserts path into paths after every path that isn't longer than it
+func insertPath(paths [][]Node, path []Node) [][]Node {
+	idx := len(paths)
+	for idx > 0 && len(paths[idx-1]) > len(path) {
+		idx--
+	}
+	paths = append(paths, nil)
+	copy(paths[idx+1:], paths[idx:])
+	paths[idx] = path
+	return paths
+}
+
+func containsPath(paths [][]Node, path []Node) bool {
+	for _, p := range paths {
+		if nodesEqual(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodesEqual(a, b []Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
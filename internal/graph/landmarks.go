@@ -0,0 +1,244 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/fnv"
+	"math"
+)
+
+const (
+	// landmarkUnreachable is the distance stored for nodes a landmark can't reach
+	landmarkUnreachable = math.MaxUint8
+
+	// landmarkSaturated is the distance stored for nodes too far from a landmark to record. It doesn't
+	// bound anything.
+	landmarkSaturated = math.MaxUint8 - 1
+)
+
+// Landmarks holds the hop distance from a few landmark nodes to every node in a graph.
+//  Distances to landmarks bound the distance between any two nodes without searching. When the bounds
+//  meet, they are the distance. Landmarks assume every edge goes both ways.
+type Landmarks struct {
+	nodes []Node
+
+	// distances holds each node's distance to every landmark. A node's distances are next to each other
+	// so that bounding a node's distance only touches one small block of memory.
+	distances []uint8
+
+	// fingerprint identifies the graph the landmarks were built from
+	fingerprint uint64
+}
+
+// BuildLandmarks picks up to count landmarks and records their distance to every node.
+//  The first landmark is the node with the most neighbors. Each landmark after that is the node that is
+//  farthest from the landmarks before it.
+func (g *Graph) BuildLandmarks(count int) *Landmarks {
+	size := len(g.edgeIndex) - 1
+	if count > size {
+		count = size
+	}
+	l := Landmarks{
+		fingerprint: g.fingerprint(),
+	}
+	if count <= 0 {
+		return &l
+	}
+	l.distances = make([]uint8, size*count)
+	nearest := make([]uint8, size)
+	level := make([]uint8, size)
+	var first Node
+	for n := 1; n < size; n++ {
+		if len(g.NodeNeighbors(Node(n))) > len(g.NodeNeighbors(first)) {
+			first = Node(n)
+		}
+	}
+	next := first
+	for i := 0; i < count; i++ {
+		l.nodes = append(l.nodes, next)
+		g.landmarkLevels(next, level)
+		for n, d := range level {
+			l.distances[n*count+i] = d
+			if i == 0 || d < nearest[n] {
+				nearest[n] = d
+			}
+		}
+		// the next landmark is the reachable node farthest from its nearest landmark
+		var farthest uint8
+		for n, d := range nearest {
+			if d > farthest && d < landmarkSaturated {
+				farthest, next = d, Node(n)
+			}
+		}
+		if farthest == 0 {
+			break
+		}
+	}
+	if len(l.nodes) < count {
+		distances := make([]uint8, size*len(l.nodes))
+		for n := 0; n < size; n++ {
+			copy(distances[n*len(l.nodes):(n+1)*len(l.nodes)], l.distances[n*count:n*count+len(l.nodes)])
+		}
+		l.distances = distances
+	}
+	return &l
+}
+
+// landmarkLevels sets level to every node's hop distance from source
+func (g *Graph) landmarkLevels(source Node, level []uint8) {
+	for i := range level {
+		level[i] = landmarkUnreachable
+	}
+	current := g.borrowLevelSlice()
+	defer g.returnLevelSlice(current)
+	next := g.borrowLevelSlice()
+	defer g.returnLevelSlice(next)
+	*current = append(*current, source)
+	level[source] = 0
+	for depth := 1; len(*current) > 0; depth++ {
+		d := uint8(landmarkSaturated)
+		if depth < landmarkSaturated {
+			d = uint8(depth)
+		}
+		*next = (*next)[:0]
+		for _, node := range *current {
+			for _, neighbor := range g.NodeNeighbors(node) {
+				if level[neighbor] == landmarkUnreachable {
+					level[neighbor] = d
+					*next = append(*next, neighbor)
+				}
+			}
+		}
+		*current, *next = *next, *current
+	}
+}
+
+// fingerprint hashes g's edges so that landmarks can tell whether they were built from g
+func (g *Graph) fingerprint() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8*1024)
+	pos := 0
+	put := func(v uint64) {
+		if pos == len(buf) {
+			_, _ = h.Write(buf)
+			pos = 0
+		}
+		binary.LittleEndian.PutUint64(buf[pos:], v)
+		pos += 8
+	}
+	for _, n := range g.edgeIndex {
+		put(uint64(n))
+	}
+	for _, n := range g.edgeTargets {
+		put(uint64(n))
+	}
+	_, _ = h.Write(buf[:pos])
+	return h.Sum64()
+}
+
+// Nodes returns the landmark nodes
+func (l *Landmarks) Nodes() []Node {
+	return l.nodes
+}
+
+// Matches returns whether l was built from g
+func (l *Landmarks) Matches(g *Graph) bool {
+	return l.covers(len(g.edgeIndex)-1) && l.fingerprint == g.fingerprint()
+}
+
+// covers returns whether l has distances for every node of a graph with size nodes
+func (l *Landmarks) covers(size int) bool {
+	return len(l.nodes) > 0 && len(l.distances) == size*len(l.nodes)
+}
+
+func (l *Landmarks) nodeDistances(n Node) []uint8 {
+	count := len(l.nodes)
+	return l.distances[int(n)*count : int(n)*count+count]
+}
+
+// DistanceBounds returns the lower and upper bounds of the hop distance between a and b.
+//  connected is false when a and b are known not to be connected. upper is -1 when no landmark
+//  reaches both nodes. When lower and upper are equal, they are the distance.
+func (l *Landmarks) DistanceBounds(a, b Node) (lower, upper int, connected bool) {
+	upper = -1
+	if int(a)*len(l.nodes) >= len(l.distances) || int(b)*len(l.nodes) >= len(l.distances) {
+		return 0, upper, true
+	}
+	aDist, bDist := l.nodeDistances(a), l.nodeDistances(b)
+	for i, da := range aDist {
+		db := bDist[i]
+		if (da == landmarkUnreachable) != (db == landmarkUnreachable) {
+			return 0, -1, false
+		}
+		if da >= landmarkSaturated || db >= landmarkSaturated {
+			continue
+		}
+		diff := int(da) - int(db)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > lower {
+			lower = diff
+		}
+		if sum := int(da) + int(db); upper < 0 || sum < upper {
+			upper = sum
+		}
+	}
+	return lower, upper, true
+}
+
+// Distance returns the hop distance between a and b when the landmarks alone can tell what it is.
+//  ok is false when the bounds don't meet or a and b aren't connected.
+func (l *Landmarks) Distance(a, b Node) (distance int, ok bool) {
+	lower, upper, connected := l.DistanceBounds(a, b)
+	if !connected || lower != upper {
+		return 0, false
+	}
+	return lower, true
+}
+
+// WithLandmarks lets a search use landmarks to give up without searching when source and dest are
+// farther apart than the search allows. Otherwise the search runs as usual, so the path it finds doesn't
+// depend on whether it has landmarks. Landmark lower bounds are too weak on graphs with short paths
+// between most nodes to rule out enough of the frontier to pay for checking it.
+// landmarks must have been built from the graph being searched.
+func WithLandmarks(landmarks *Landmarks) PathOption {
+	return func(o *pathOptions) {
+		o.landmarks = landmarks
+	}
+}
+
+type landmarksSerializer struct {
+	Nodes       []Node
+	Distances   []uint8
+	Fingerprint uint64
+}
+
+// GobDecode implements gob.GobDecoder
+func (l *Landmarks) GobDecode(p []byte) error {
+	var ls landmarksSerializer
+	err := gob.NewDecoder(bytes.NewReader(p)).Decode(&ls)
+	if err != nil {
+		return err
+	}
+	l.nodes = ls.Nodes
+	l.distances = ls.Distances
+	l.fingerprint = ls.Fingerprint
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder
+func (l *Landmarks) GobEncode() ([]byte, error) {
+	ls := landmarksSerializer{
+		Nodes:       l.nodes,
+		Distances:   l.distances,
+		Fingerprint: l.fingerprint,
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&ls)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
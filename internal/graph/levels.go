@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"math"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// topDownRatio and bottomUpRatio decide when FindLevels switches search direction. A search switches to
+	// bottom-up when the frontier has more than 1/topDownRatio of the unexplored edges and back to
+	// top-down when the frontier has fewer than 1/bottomUpRatio of the nodes.
+	topDownRatio  = 14
+	bottomUpRatio = 24
+
+	// minParallelWords is the fewest bitset words worth handing to each worker
+	minParallelWords = 256
+)
+
+// FindLevels returns the level of each node in the graph. source is level 1, its neighbors are level 2
+// and so on. Nodes that can't be reached from source are level 0. Levels above 255 are reported as 255.
+func (g *Graph) FindLevels(source Node) []uint8 {
+	levels := make([]uint8, len(g.edgeIndex)-1)
+	g.FindLevelsInto(levels, source, 1)
+	return levels
+}
+
+// BorrowLevels returns a slice from g's pool that is big enough for FindLevelsInto. Give it back with
+// ReturnLevels once its levels aren't needed.
+func (g *Graph) BorrowLevels() *[]uint8 {
+	return g.levelsPool.Get().(*[]uint8)
+}
+
+// ReturnLevels returns a slice from BorrowLevels to g's pool
+func (g *Graph) ReturnLevels(levels *[]uint8) {
+	g.levelsPool.Put(levels)
+}
+
+// FindLevelsInto is FindLevels that writes to levels instead of allocating. levels must have a value for
+//  every node in the graph. The search runs on up to workers goroutines. Zero or less means GOMAXPROCS.
+//  It is a direction optimizing breadth first search. Levels with small frontiers are searched top-down
+//  from the frontier, and levels with large frontiers are searched bottom-up by checking whether each
+//  unvisited node has a neighbor in the frontier.
+func (g *Graph) FindLevelsInto(levels []uint8, source Node, workers int) {
+	size := len(g.edgeIndex) - 1
+	levels = levels[:size]
+	for i := range levels {
+		levels[i] = 0
+	}
+	if source >= Node(size) {
+		return
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	visited := g.borrowBitset()
+	defer g.returnBitset(visited)
+	frontier := g.borrowBitset()
+	defer g.returnBitset(frontier)
+	next := g.borrowBitset()
+	defer g.returnBitset(next)
+	s := levelSearch{
+		g:        g,
+		levels:   levels,
+		visited:  *visited,
+		frontier: *frontier,
+		next:     *next,
+		workers:  workers,
+	}
+	s.visited.set(source)
+	s.frontier.set(source)
+	levels[source] = 1
+
+	frontierSize := 1
+	frontierEdges := len(g.NodeNeighbors(source))
+	unexploredEdges := len(g.edgeTargets) - frontierEdges
+	bottomUp := false
+	for depth := 2; frontierSize > 0; depth++ {
+		level := uint8(math.MaxUint8)
+		if depth < math.MaxUint8 {
+			level = uint8(depth)
+		}
+		switch {
+		case !bottomUp && frontierEdges > unexploredEdges/topDownRatio:
+			bottomUp = true
+		case bottomUp && frontierSize < size/bottomUpRatio:
+			bottomUp = false
+		}
+		if bottomUp {
+			s.run(func(start, end int) levelCount { return s.bottomUp(start, end, level) })
+		} else {
+			s.run(func(start, end int) levelCount { return s.topDown(start, end, level) })
+		}
+		frontierSize, frontierEdges = s.count.nodes, s.count.edges
+		unexploredEdges -= frontierEdges
+		s.frontier, s.next = s.next, s.frontier
+		for i := range s.next {
+			s.next[i] = 0
+		}
+	}
+}
+
+// bitset is a set of nodes with one bit per node
+type bitset []uint32
+
+func (b bitset) contains(node Node) bool {
+	return b[node>>nodeSetBucketBits]&(1<<(node&nodeSetBucketMask)) != 0
+}
+
+func (b bitset) set(node Node) {
+	b[node>>nodeSetBucketBits] |= 1 << (node & nodeSetBucketMask)
+}
+
+func (b bitset) unset(node Node) {
+	b[node>>nodeSetBucketBits] &^= 1 << (node & nodeSetBucketMask)
+}
+
+func (b bitset) containsAtomic(node Node) bool {
+	return atomic.LoadUint32(&b[node>>nodeSetBucketBits])&(1<<(node&nodeSetBucketMask)) != 0
+}
+
+// setAtomic sets node and reports whether it wasn't already set
+func (b bitset) setAtomic(node Node) bool {
+	word := &b[node>>nodeSetBucketBits]
+	bit := uint32(1) << (node & nodeSetBucketMask)
+	for {
+		old := atomic.LoadUint32(word)
+		if old&bit != 0 {
+			return false
+		}
+		if atomic.CompareAndSwapUint32(word, old, old|bit) {
+			return true
+		}
+	}
+}
+
+// levelCount is the number of nodes found for the next level and the sum of their neighbor counts
+type levelCount struct {
+	nodes, edges int
+}
+
+type levelSearch struct {
+	g                       *Graph
+	levels                  []uint8
+	visited, frontier, next bitset
+	workers                 int
+	count                   levelCount
+}
+
+// run calls fn for ranges of bitset words on up to s.workers goroutines and sets s.count to the total
+// of their counts
+func (s *levelSearch) run(fn func(start, end int) levelCount) {
+	words := len(s.visited)
+	workers := s.workers
+	if workers > words/minParallelWords {
+		workers = words / minParallelWords
+	}
+	if workers <= 1 {
+		s.count = fn(0, words)
+		return
+	}
+	counts := make([]levelCount, workers)
+	chunk := (words + workers - 1) / workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		start, end := i*chunk, (i+1)*chunk
+		if end > words {
+			end = words
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counts[i] = fn(start, end)
+		}(i)
+	}
+	wg.Wait()
+	s.count = levelCount{}
+	for _, c := range counts {
+		s.count.nodes += c.nodes
+		s.count.edges += c.edges
+	}
+}
+
+// topDown visits the unvisited neighbors of frontier nodes in words start to end. Other workers may
+// visit the same neighbors, so visited and next are updated atomically when there are other workers.
+func (s *levelSearch) topDown(start, end int, level uint8) levelCount {
+	var count levelCount
+	parallel := s.workers > 1 && end-start < len(s.visited)
+	for w := start; w < end; w++ {
+		word := s.frontier[w]
+		for word != 0 {
+			node := Node(w<<nodeSetBucketBits + bits.TrailingZeros32(word))
+			word &= word - 1
+			for _, neighbor := range s.g.NodeNeighbors(node) {
+				if parallel {
+					if s.visited.containsAtomic(neighbor) || !s.visited.setAtomic(neighbor) {
+						continue
+					}
+					s.next.setAtomic(neighbor)
+				} else {
+					if s.visited.contains(neighbor) {
+						continue
+					}
+					s.visited.set(neighbor)
+					s.next.set(neighbor)
+				}
+				s.levels[neighbor] = level
+				count.nodes++
+				count.edges += len(s.g.NodeNeighbors(neighbor))
+			}
+		}
+	}
+	return count
+}
+
+// bottomUp visits the unvisited nodes in words start to end that have a neighbor in frontier. Each
+// worker only writes its own words, so nothing needs to be atomic.
+func (s *levelSearch) bottomUp(start, end int, level uint8) levelCount {
+	var count levelCount
+	size := len(s.levels)
+	for w := start; w < end; w++ {
+		unvisited := ^s.visited[w]
+		for unvisited != 0 {
+			node := Node(w<<nodeSetBucketBits + bits.TrailingZeros32(unvisited))
+			unvisited &= unvisited - 1
+			if int(node) >= size {
+				break
+			}
+			neighbors := s.g.NodeNeighbors(node)
+			for _, neighbor := range neighbors {
+				if !s.frontier.contains(neighbor) {
+					continue
+				}
+				s.visited.set(node)
+				s.next.set(node)
+				s.levels[node] = level
+				count.nodes++
+				count.edges += len(neighbors)
+				break
+			}
+		}
+	}
+	return count
+}
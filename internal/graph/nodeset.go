@@ -41,3 +41,13 @@ func (p *parentsMap) setParent(node, parent Node) {
 func (p *parentsMap) getParent(node Node) Node {
 	return p.parents[node]
 }
+
+// setLevel marks node as visited and stores its BFS level in place of a parent
+func (p *parentsMap) setLevel(node Node, level int) {
+	p.setParent(node, Node(level))
+}
+
+// getLevel returns the level stored by setLevel
+func (p *parentsMap) getLevel(node Node) int {
+	return int(p.parents[node])
+}
@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"sort"
+)
+
+// NeighborOrder is every node's neighbors precomputed in priority order. Searches using a
+// NeighborOrder don't need to call a PriorityFunc or sort anything.
+type NeighborOrder struct {
+	edgeIndex   []int
+	edgeTargets []Node
+}
+
+// OrderNeighbors calls fn once per node and returns a NeighborOrder with each node's neighbors
+// sorted by priority, highest first. Neighbors with the same priority keep their order from g.
+func (g *Graph) OrderNeighbors(fn PriorityFunc) *NeighborOrder {
+	size := len(g.edgeIndex) - 1
+	priorities := make([]int64, size)
+	for n := 0; n < size; n++ {
+		priorities[n] = fn(Node(n))
+	}
+	order := NeighborOrder{
+		edgeIndex:   g.edgeIndex,
+		edgeTargets: make([]Node, len(g.edgeTargets)),
+	}
+	copy(order.edgeTargets, g.edgeTargets)
+	for n := 0; n < size; n++ {
+		neighbors := order.NodeNeighbors(Node(n))
+		sort.SliceStable(neighbors, func(i, j int) bool {
+			return priorities[neighbors[i]] > priorities[neighbors[j]]
+		})
+	}
+	return &order
+}
+
+// NodeNeighbors returns n's immediate neighbors in priority order
+func (o *NeighborOrder) NodeNeighbors(n Node) []Node {
+	start, end := o.edgeIndex[n], o.edgeIndex[n+1]
+	return o.edgeTargets[start:end]
+}
+
+// WithNeighborOrder makes a search visit neighbors in order's priority order. It takes the place
+// of the search's PriorityFunc. order must have been created from the graph being searched.
+func WithNeighborOrder(order *NeighborOrder) PathOption {
+	return func(o *pathOptions) {
+		o.order = order
+	}
+}
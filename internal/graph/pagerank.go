@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"math"
+)
+
+// PageRankOptions configures PageRank and PersonalizedPageRank. Zero values get the defaults.
+type PageRankOptions struct {
+	// Damping is the probability that the random walk follows an edge instead of jumping back to its
+	//  start. The default is 0.85.
+	Damping float64
+
+	// Tolerance decides when the scores have converged. PageRank stops when the scores change by less than
+	//  Tolerance in total. PersonalizedPageRank stops when no node has more than Tolerance times its
+	//  neighbor count left to push. The default is 1e-6.
+	Tolerance float64
+
+	// MaxIterations limits the number of iterations PageRank runs whether or not it has converged. The
+	//  default is 100. It doesn't apply to PersonalizedPageRank.
+	MaxIterations int
+}
+
+func (o *PageRankOptions) withDefaults() PageRankOptions {
+	opts := PageRankOptions{
+		Damping:       0.85,
+		Tolerance:     1e-6,
+		MaxIterations: 100,
+	}
+	if o == nil {
+		return opts
+	}
+	if o.Damping > 0 {
+		opts.Damping = o.Damping
+	}
+	if o.Tolerance > 0 {
+		opts.Tolerance = o.Tolerance
+	}
+	if o.MaxIterations > 0 {
+		opts.MaxIterations = o.MaxIterations
+	}
+	return opts
+}
+
+// PageRank returns the PageRank of every node and the number of iterations it took to converge. The
+//  scores sum to 1. A random walk from a node without neighbors jumps to a random node.
+func (g *Graph) PageRank(options *PageRankOptions) ([]float64, int) {
+	opts := options.withDefaults()
+	size := len(g.edgeIndex) - 1
+	if size == 0 {
+		return nil, 0
+	}
+	ranks := make([]float64, size)
+	next := make([]float64, size)
+	for i := range ranks {
+		ranks[i] = 1 / float64(size)
+	}
+	iterations := 0
+	for iterations < opts.MaxIterations {
+		iterations++
+		var dangling float64
+		for i := range next {
+			next[i] = 0
+		}
+		for n, rank := range ranks {
+			neighbors := g.NodeNeighbors(Node(n))
+			if len(neighbors) == 0 {
+				dangling += rank
+				continue
+			}
+			share := rank / float64(len(neighbors))
+			for _, neighbor := range neighbors {
+				next[neighbor] += share
+			}
+		}
+		jump := (1-opts.Damping)/float64(size) + opts.Damping*dangling/float64(size)
+		var change float64
+		for i := range next {
+			next[i] = opts.Damping*next[i] + jump
+			change += math.Abs(next[i] - ranks[i])
+		}
+		ranks, next = next, ranks
+		if change < opts.Tolerance {
+			break
+		}
+	}
+	return ranks, iterations
+}
+
+// PersonalizedPageRank approximates the PageRank of a random walk that always jumps back to source. It
+//  scores how strongly every node is associated with source. It uses the local push algorithm, so it
+//  only visits the part of the graph near source, and only nodes with a score are returned. The
+//  scores sum to slightly less than 1 because the walks that haven't converged are left out.
+func (g *Graph) PersonalizedPageRank(source Node, options *PageRankOptions) map[Node]float64 {
+	opts := options.withDefaults()
+	scores := map[Node]float64{}
+	residuals := map[Node]float64{source: 1}
+	queue := []Node{source}
+	queued := map[Node]bool{source: true}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		delete(queued, node)
+		residual := residuals[node]
+		delete(residuals, node)
+		neighbors := g.NodeNeighbors(node)
+		if len(neighbors) == 0 {
+			scores[node] += residual
+			continue
+		}
+		scores[node] += (1 - opts.Damping) * residual
+		share := opts.Damping * residual / float64(len(neighbors))
+		for _, neighbor := range neighbors {
+			residuals[neighbor] += share
+			if queued[neighbor] {
+				continue
+			}
+			if residuals[neighbor] > opts.Tolerance*float64(len(g.NodeNeighbors(neighbor))) {
+				queued[neighbor] = true
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return scores
+}
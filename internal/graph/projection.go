@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Projection links the nodes of a graph that share a neighbor, like cast members who share a movie in
+//  a graph of cast members and movies. Each edge's weight is the number of neighbors its nodes share.
+//  A Projection keeps the node ids of the graph it was projected from. Nodes that weren't projected are
+//  still there, but have no neighbors.
+type Projection struct {
+	*Graph
+
+	// weights holds the weight of each edge in Graph.edgeTargets
+	weights []uint32
+}
+
+// Project links the nodes that include returns true for whenever they share a neighbor. The shared
+//  neighbors are usually the nodes include returns false for, but they don't need to be.
+func (g *Graph) Project(include func(Node) bool) *Projection {
+	size := len(g.edgeIndex) - 1
+	counts := make([]uint32, size)
+	var touched []Node
+	// share sets touched to the included nodes that share a neighbor with node and counts how many
+	// neighbors each one shares in counts. The caller resets counts.
+	share := func(node Node) {
+		touched = touched[:0]
+		for _, shared := range g.NodeNeighbors(node) {
+			for _, neighbor := range g.NodeNeighbors(shared) {
+				if neighbor == node || !include(neighbor) {
+					continue
+				}
+				if counts[neighbor] == 0 {
+					touched = append(touched, neighbor)
+				}
+				counts[neighbor]++
+			}
+		}
+	}
+
+	// The first pass only counts each node's edges so that the edges are allocated once. Projections
+	// are often many times bigger than the graph they come from, so growing them as they're found
+	// would copy them several times over.
+	edgeIndex := make([]int, size+1)
+	for n := 0; n < size; n++ {
+		node := Node(n)
+		edgeIndex[n+1] = edgeIndex[n]
+		if !include(node) {
+			continue
+		}
+		share(node)
+		edgeIndex[n+1] += len(touched)
+		for _, neighbor := range touched {
+			counts[neighbor] = 0
+		}
+	}
+	p := Projection{
+		Graph: &Graph{
+			edgeIndex:   edgeIndex,
+			edgeTargets: make([]Node, 0, edgeIndex[size]),
+		},
+		weights: make([]uint32, 0, edgeIndex[size]),
+	}
+	for n := 0; n < size; n++ {
+		node := Node(n)
+		if !include(node) {
+			continue
+		}
+		share(node)
+		sort.Slice(touched, func(i, j int) bool {
+			return touched[i] < touched[j]
+		})
+		for _, neighbor := range touched {
+			p.edgeTargets = append(p.edgeTargets, neighbor)
+			p.weights = append(p.weights, counts[neighbor])
+			counts[neighbor] = 0
+		}
+	}
+	p.createPools()
+	return &p
+}
+
+// NeighborWeights returns the weights of the edges to n's neighbors in the same order as NodeNeighbors
+func (p *Projection) NeighborWeights(n Node) []uint32 {
+	start, end := p.edgeIndex[n], p.edgeIndex[n+1]
+	return p.weights[start:end]
+}
+
+// Weight returns the weight of the edge between a and b, or zero when they aren't neighbors
+func (p *Projection) Weight(a, b Node) int {
+	neighbors := p.NodeNeighbors(a)
+	i := sort.Search(len(neighbors), func(i int) bool {
+		return neighbors[i] >= b
+	})
+	if i == len(neighbors) || neighbors[i] != b {
+		return 0
+	}
+	return int(p.NeighborWeights(a)[i])
+}
@@ -0,0 +1,128 @@
+package graph
+
+import (
+	"container/heap"
+)
+
+// CostFunc returns the cost of stepping from node to neighbor. Costs must not be negative.
+type CostFunc func(node, neighbor Node) float64
+
+// WeightedPath finds the lowest cost path from source to dest using Dijkstra's algorithm.
+//  It returns the path's total cost. When several paths have the lowest cost, WeightedPath may return
+//  any one of them. A NeighborOrder from opts decides which is found first.
+//  path - is a pointer to a slice that WeightedPath will set to the found path
+//  When no path is found, path will be set to zero length and the returned PathOutcome explains why.
+//  opts can restrict which nodes the path may use.
+//  WeightedPath never modifies g, so it is safe to call from multiple goroutines.
+func (g *Graph) WeightedPath(path *[]Node, source, dest Node, cost CostFunc, opts ...PathOption) (float64, PathOutcome) {
+	setPathLen(path, 0)
+	size := len(g.edgeIndex) - 1
+	if source >= Node(size) || dest >= Node(size) {
+		return 0, InvalidNode
+	}
+	filter := g.newSearchFilter(opts)
+	defer g.releaseSearchFilter(filter)
+	if filter.blocks(source) || filter.blocks(dest) || !g.Components().Connected(source, dest) {
+		return 0, NoPathExists
+	}
+	if source == dest {
+		*path = append(*path, source)
+		return 0, PathFound
+	}
+	filtering := filter.restricts()
+	order := filter.neighborOrder()
+
+	// parents holds every node that has been reached. costs is only valid for nodes in parents.
+	parents := g.borrowParentsMap()
+	defer g.returnParentsMap(parents)
+	settled := g.borrowParentsMap()
+	defer g.returnParentsMap(settled)
+	costs := g.borrowCostSlice()
+	defer g.returnCostSlice(costs)
+
+	queue := costQueue{{node: source}}
+	pushed := 0
+	parents.setParent(source, source)
+	costs[source] = 0
+	for len(queue) > 0 {
+		item := heap.Pop(&queue).(costQueueItem)
+		node := item.node
+		if settled.contains(node) {
+			continue
+		}
+		if node == dest {
+			for n := dest; n != source; n = parents.getParent(n) {
+				*path = append(*path, n)
+			}
+			*path = append(*path, source)
+			reverseNodes(*path)
+			return item.cost, PathFound
+		}
+		settled.setParent(node, 0)
+		var neighbors []Node
+		if order != nil {
+			neighbors = order.NodeNeighbors(node)
+		} else {
+			neighbors = g.NodeNeighbors(node)
+		}
+		for _, neighbor := range neighbors {
+			if settled.contains(neighbor) || filtering && !filter.allows(node, neighbor) {
+				continue
+			}
+			c := item.cost + cost(node, neighbor)
+			if parents.contains(neighbor) && c >= costs[neighbor] {
+				continue
+			}
+			parents.setParent(neighbor, node)
+			costs[neighbor] = c
+			pushed++
+			heap.Push(&queue, costQueueItem{node: neighbor, cost: c, seq: pushed})
+		}
+	}
+	return 0, NoPathExists
+}
+
+func (g *Graph) borrowCostSlice() []float64 {
+	return *g.costPool.Get().(*[]float64)
+}
+
+func (g *Graph) returnCostSlice(costs []float64) {
+	g.costPool.Put(&costs)
+}
+
+func reverseNodes(nodes []Node) {
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+}
+
+type costQueueItem struct {
+	node Node
+	cost float64
+
+	// seq is the order the item was pushed in
+	seq int
+}
+
+// costQueue is a min-heap of nodes by cost. Nodes with equal cost come out in the order they were pushed.
+type costQueue []costQueueItem
+
+func (q costQueue) Len() int { return len(q) }
+
+func (q costQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q costQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *costQueue) Push(x interface{}) { *q = append(*q, x.(costQueueItem)) }
+
+func (q *costQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,24 @@
+package baconator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// writeJSONFile writes v to filename as json
+func writeJSONFile(filename string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0o600)
+}
+
+// readJSONFile decodes the json in filename into v
+func readJSONFile(filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filename) //nolint:gosec // not user supplied
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
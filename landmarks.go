@@ -0,0 +1,52 @@
+package baconator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// landmarkCount is the number of landmarks BuildLandmarks picks
+const landmarkCount = 16
+
+// BuildLandmarks builds b.Landmarks. It takes one search per landmark, so save the result with
+// WriteLandmarks and use LoadLandmarks instead of building it every time the data is loaded.
+func (b *Baconator) BuildLandmarks() {
+	b.Landmarks = b.Graph.BuildLandmarks(landmarkCount)
+}
+
+// WriteLandmarks writes b.Landmarks to filename
+func (b *Baconator) WriteLandmarks(filename string) error {
+	if b.Landmarks == nil {
+		return errors.New("no landmarks to write")
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(b.Landmarks)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf.Bytes(), 0o600)
+}
+
+// LoadLandmarks sets b.Landmarks to landmarks written by WriteLandmarks. It returns an error without
+// changing b when they were built from different data.
+func (b *Baconator) LoadLandmarks(filename string) error {
+	data, err := ioutil.ReadFile(filename) //nolint:gosec // not user supplied
+	if err != nil {
+		return err
+	}
+	var landmarks graph.Landmarks
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&landmarks)
+	if err != nil {
+		return err
+	}
+	if !landmarks.Matches(b.Graph) {
+		return fmt.Errorf("landmarks in %s were built from different data", filename)
+	}
+	b.Landmarks = &landmarks
+	return nil
+}
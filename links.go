@@ -0,0 +1,433 @@
+package baconator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+type linksResult struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// defaultMaxHops is the default limit on the number of movies in a link
+const defaultMaxHops = 49
+
+type linkOptions struct {
+	// maxHops is the most movies allowed in a path. Zero means defaultMaxHops.
+	maxHops int
+
+	// k is the number of paths to find. Values less than 2 find only the shortest path.
+	k int
+
+	// excludeCast and excludeMovies are names that may not appear in a path
+	excludeCast   []string
+	excludeMovies []string
+
+	// fromYear and toYear limit paths to movies released in that range. Zero means unbounded.
+	fromYear, toYear int
+
+	// strategy is the name of the pathStrategy used to choose between equally short paths
+	strategy string
+
+	// weighted finds the lowest cost path instead of the path with the fewest hops. cost names the
+	// link cost to use. Empty means costCastSize. maxHops and k don't apply to weighted paths.
+	weighted bool
+	cost     string
+}
+
+func (o *linkOptions) maxPathLength() int {
+	maxHops := defaultMaxHops
+	if o != nil && o.maxHops > 0 {
+		maxHops = o.maxHops
+	}
+	return 2*maxHops + 1
+}
+
+// noPathError is returned by links when there is no path to return
+type noPathError struct {
+	src, dest string
+
+	// maxHops is set when the search was abandoned at maxHops and a longer path may exist
+	maxHops int
+}
+
+func (e *noPathError) Error() string {
+	if e.maxHops > 0 {
+		return fmt.Sprintf("no link between %q and %q within %d hops", e.src, e.dest, e.maxHops)
+	}
+	return fmt.Sprintf("%q and %q are not linked", e.src, e.dest)
+}
+
+type unknownMovieError struct {
+	title string
+}
+
+func (e *unknownMovieError) Error() string {
+	return fmt.Sprintf("unknown movie: %q", e.title)
+}
+
+// link costs that weighted links can minimize
+const (
+	costCastSize      = "cast_size"
+	costRecency       = "recency"
+	costCollaboration = "collaboration"
+)
+
+type unknownCostError struct {
+	name string
+}
+
+func (e *unknownCostError) Error() string {
+	return fmt.Sprintf("unknown cost %q. valid costs are: %s, %s, %s", e.name, costCastSize, costRecency, costCollaboration)
+}
+
+// noProjectionError is returned for a cost that needs the projected graph when it hasn't been built
+type noProjectionError struct {
+	cost string
+}
+
+func (e *noProjectionError) Error() string {
+	return fmt.Sprintf("cost %q needs the projected graph", e.cost)
+}
+
+// unsupportedCostOptionError is returned for a link option that can't be used with a cost. param is
+// the option's query parameter.
+type unsupportedCostOptionError struct {
+	cost  string
+	param string
+}
+
+func (e *unsupportedCostOptionError) Error() string {
+	return fmt.Sprintf("%s is not supported when cost is %s", e.param, e.cost)
+}
+
+// excludedEndpointError is returned when a link's own end is excluded
+type excludedEndpointError struct {
+	name string
+}
+
+func (e *excludedEndpointError) Error() string {
+	return fmt.Sprintf("%q is an end of the link and can't be excluded", e.name)
+}
+
+// pathOptions resolves the exclusions in opts to graph.PathOptions
+func (b *Baconator) pathOptions(opts *linkOptions, src, dest *nameMatch) ([]graph.PathOption, error) {
+	var pathOpts []graph.PathOption
+	if b.Landmarks != nil {
+		pathOpts = append(pathOpts, graph.WithLandmarks(b.Landmarks))
+	}
+	if opts == nil {
+		return pathOpts, nil
+	}
+	if opts.fromYear != 0 || opts.toYear != 0 {
+		pathOpts = append(pathOpts, graph.FilterNodes(b.yearFilter(opts.fromYear, opts.toYear)))
+	}
+	if len(opts.excludeCast)+len(opts.excludeMovies) == 0 {
+		return pathOpts, nil
+	}
+	excluded, err := b.excludedCast(opts, src, dest)
+	if err != nil {
+		return nil, err
+	}
+	for _, title := range opts.excludeMovies {
+		node, ok := b.MovieNodes[title]
+		if !ok {
+			return nil, &unknownMovieError{title: title}
+		}
+		excluded = append(excluded, node)
+	}
+	return append(pathOpts, graph.ExcludeNodes(excluded...)), nil
+}
+
+// excludedCast resolves opts.excludeCast to nodes
+func (b *Baconator) excludedCast(opts *linkOptions, src, dest *nameMatch) ([]graph.Node, error) {
+	if opts == nil || len(opts.excludeCast) == 0 {
+		return nil, nil
+	}
+	excluded := make([]graph.Node, 0, len(opts.excludeCast))
+	for _, name := range opts.excludeCast {
+		// a misspelled exclusion must not quietly exclude somebody else
+		match, ok := b.resolveCastStrictly(name)
+		if !ok {
+			return nil, b.unknownCast(name)
+		}
+		if match.Node == src.Node || match.Node == dest.Node {
+			return nil, &excludedEndpointError{name: match.Name}
+		}
+		excluded = append(excluded, match.Node)
+	}
+	return excluded, nil
+}
+
+// yearFilter only allows movies released between from and to inclusive. Movies with an unknown year
+// are never allowed. Zero leaves either end of the range open.
+func (b *Baconator) yearFilter(from, to int) graph.NodeFilter {
+	b.loadIndexes()
+	return func(node graph.Node) bool {
+		if b.NodeInfo[node].Type != movieNode {
+			return true
+		}
+		year := int(b.years[node])
+		return year != 0 && (from == 0 || year >= from) && (to == 0 || year <= to)
+	}
+}
+
+// outcomeError returns the error for a graph search that ended with outcome
+func outcomeError(outcome graph.PathOutcome, src, dest *nameMatch, opts *linkOptions) error {
+	switch outcome {
+	case graph.PathFound:
+		return nil
+	case graph.MaxPathLengthExceeded:
+		return &noPathError{src: src.Name, dest: dest.Name, maxHops: opts.maxPathLength() / 2}
+	default:
+		return &noPathError{src: src.Name, dest: dest.Name}
+	}
+}
+
+type linkResult struct {
+	A    *nameMatch    `json:"a"`
+	B    *nameMatch    `json:"b"`
+	Path []linksResult `json:"path"`
+
+	// Paths holds the k shortest paths when more than one was requested
+	Paths [][]linksResult `json:"paths,omitempty"`
+
+	// Cost is the path's total cost for weighted links
+	Cost *float64 `json:"cost,omitempty"`
+}
+
+// resolvePair resolves the cast members at both ends of a link
+func (b *Baconator) resolvePair(src, dest string) (srcMatch, destMatch *nameMatch, err error) {
+	srcMatch, err = b.resolveCast(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	destMatch, err = b.resolveCast(dest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return srcMatch, destMatch, nil
+}
+
+func (b *Baconator) links(src, dest string, opts *linkOptions) (*linkResult, error) {
+	srcMatch, destMatch, err := b.resolvePair(src, dest)
+	if err != nil {
+		return nil, err
+	}
+	var strategy string
+	if opts != nil {
+		strategy = opts.strategy
+	}
+	order, err := b.neighborOrder(strategy)
+	if err != nil {
+		return nil, err
+	}
+	pathOpts, err := b.pathOptions(opts, srcMatch, destMatch)
+	if err != nil {
+		return nil, err
+	}
+	pathOpts = append(pathOpts, graph.WithNeighborOrder(order))
+	if opts != nil && opts.weighted && opts.cost == costCollaboration {
+		return b.collaborationLinks(srcMatch, destMatch, opts, order)
+	}
+	if opts != nil && opts.weighted {
+		return b.weightedLinks(srcMatch, destMatch, opts.cost, pathOpts)
+	}
+	if opts != nil && opts.k > 1 {
+		return b.kLinks(srcMatch, destMatch, opts, pathOpts)
+	}
+	var path []graph.Node
+	outcome := b.Graph.FindPath(&path, opts.maxPathLength(), srcMatch.Node, destMatch.Node, nil, pathOpts...)
+	err = outcomeError(outcome, srcMatch, destMatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &linkResult{
+		A:    srcMatch,
+		B:    destMatch,
+		Path: b.pathResult(path),
+	}, nil
+}
+
+// kLinks finds the opts.k shortest loopless links between src and dest
+func (b *Baconator) kLinks(src, dest *nameMatch, opts *linkOptions, pathOpts []graph.PathOption) (*linkResult, error) {
+	paths, outcome := b.Graph.KShortestPaths(opts.k, opts.maxPathLength(), src.Node, dest.Node, nil, pathOpts...)
+	err := outcomeError(outcome, src, dest, opts)
+	if err != nil {
+		return nil, err
+	}
+	res := linkResult{
+		A:     src,
+		B:     dest,
+		Paths: make([][]linksResult, len(paths)),
+	}
+	for i, path := range paths {
+		res.Paths[i] = b.pathResult(path)
+	}
+	res.Path = res.Paths[0]
+	return &res, nil
+}
+
+// weightedLinks finds the link between src and dest with the lowest total of the named link cost
+func (b *Baconator) weightedLinks(src, dest *nameMatch, costName string, pathOpts []graph.PathOption) (*linkResult, error) {
+	costFunc, err := b.costFunc(costName)
+	if err != nil {
+		return nil, err
+	}
+	var path []graph.Node
+	cost, outcome := b.Graph.WeightedPath(&path, src.Node, dest.Node, costFunc, pathOpts...)
+	err = outcomeError(outcome, src, dest, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &linkResult{
+		A:    src,
+		B:    dest,
+		Path: b.pathResult(path),
+		Cost: &cost,
+	}, nil
+}
+
+// collaborationLinks finds the link between src and dest for costCollaboration. Stepping between cast
+// members who share k movies costs 1/k, so cast members who worked together often make the strongest
+// links. It searches b.projection, which only has cast members, so it returns an error when opts
+// excludes movies or sets a year range. The movie between each pair of cast members is the first they
+// share in order.
+func (b *Baconator) collaborationLinks(src, dest *nameMatch, opts *linkOptions, order *graph.NeighborOrder) (*linkResult, error) {
+	switch {
+	case len(opts.excludeMovies) > 0:
+		return nil, &unsupportedCostOptionError{cost: costCollaboration, param: "exclude_movie"}
+	case opts.fromYear != 0:
+		return nil, &unsupportedCostOptionError{cost: costCollaboration, param: "from_year"}
+	case opts.toYear != 0:
+		return nil, &unsupportedCostOptionError{cost: costCollaboration, param: "to_year"}
+	}
+	if b.projection == nil {
+		return nil, &noProjectionError{cost: costCollaboration}
+	}
+	excluded, err := b.excludedCast(opts, src, dest)
+	if err != nil {
+		return nil, err
+	}
+	var castPath []graph.Node
+	cost, outcome := b.projection.WeightedPath(&castPath, src.Node, dest.Node, b.collaborationCost, graph.ExcludeNodes(excluded...))
+	err = outcomeError(outcome, src, dest, nil)
+	if err != nil {
+		return nil, err
+	}
+	path := make([]graph.Node, 0, 2*len(castPath)-1)
+	path = append(path, castPath[0])
+	for i := 1; i < len(castPath); i++ {
+		path = append(path, b.sharedMovie(castPath[i-1], castPath[i], order), castPath[i])
+	}
+	return &linkResult{
+		A:    src,
+		B:    dest,
+		Path: b.pathResult(path),
+		Cost: &cost,
+	}, nil
+}
+
+// collaborationCost is the graph.CostFunc for costCollaboration on b.projection
+func (b *Baconator) collaborationCost(node, neighbor graph.Node) float64 {
+	return 1 / float64(b.projection.Weight(node, neighbor))
+}
+
+// sharedMovie returns the first of a's movies in order that b is also in. a and b must share a movie.
+func (b *Baconator) sharedMovie(a, other graph.Node, order *graph.NeighborOrder) graph.Node {
+	for _, film := range order.NodeNeighbors(a) {
+		cast := b.Graph.NodeNeighbors(film)
+		i := sort.Search(len(cast), func(i int) bool {
+			return cast[i] >= other
+		})
+		if i < len(cast) && cast[i] == other {
+			return film
+		}
+	}
+	panic("cast members don't share a movie")
+}
+
+// costFunc returns the graph.CostFunc for the named link cost. An empty name is costCastSize.
+func (b *Baconator) costFunc(name string) (graph.CostFunc, error) {
+	switch name {
+	case "", costCastSize:
+		return b.castSizeCost, nil
+	case costRecency:
+		b.loadIndexes()
+		return b.recencyCost, nil
+	default:
+		return nil, &unknownCostError{name: name}
+	}
+}
+
+// castSizeCost is the graph.CostFunc for costCastSize. Stepping onto a movie costs log2 of the size of
+// its cast, so a two person movie costs 1 and larger casts cost more. Cast members of small casts are
+// more likely to have worked together closely.
+func (b *Baconator) castSizeCost(_, neighbor graph.Node) float64 {
+	if b.NodeInfo[neighbor].Type != movieNode {
+		return 0
+	}
+	return math.Log2(float64(b.degree(neighbor)))
+}
+
+// recencyCost is the graph.CostFunc for costRecency. Stepping onto a movie from the newest year costs
+// 1, and every ten years older costs 1 more, so recent collaborations are the strongest. Movies with an
+// unknown year cost as much as the oldest.
+func (b *Baconator) recencyCost(_, neighbor graph.Node) float64 {
+	if b.NodeInfo[neighbor].Type != movieNode {
+		return 0
+	}
+	year := b.years[neighbor]
+	if year == 0 {
+		year = b.oldestYear
+	}
+	return 1 + float64(b.newestYear-year)/10
+}
+
+func (b *Baconator) pathResult(path []graph.Node) []linksResult {
+	res := make([]linksResult, len(path))
+	for i, node := range path {
+		info := b.NodeInfo[node]
+		res[i].Name = info.Name
+		res[i].Type = info.Type.String()
+	}
+	return res
+}
+
+type allLinksResult struct {
+	A     *nameMatch      `json:"a"`
+	B     *nameMatch      `json:"b"`
+	Count uint64          `json:"count"`
+	Paths [][]linksResult `json:"paths"`
+}
+
+// allLinks finds the number of shortest links between src and dest and returns up to limit of them
+func (b *Baconator) allLinks(src, dest string, limit int, opts *linkOptions) (*allLinksResult, error) {
+	srcMatch, destMatch, err := b.resolvePair(src, dest)
+	if err != nil {
+		return nil, err
+	}
+	pathOpts, err := b.pathOptions(opts, srcMatch, destMatch)
+	if err != nil {
+		return nil, err
+	}
+	paths, count, outcome := b.Graph.AllShortestPaths(opts.maxPathLength(), srcMatch.Node, destMatch.Node, limit, pathOpts...)
+	err = outcomeError(outcome, srcMatch, destMatch, opts)
+	if err != nil {
+		return nil, err
+	}
+	res := allLinksResult{
+		A:     srcMatch,
+		B:     destMatch,
+		Count: count,
+		Paths: make([][]linksResult, len(paths)),
+	}
+	for i, path := range paths {
+		res.Paths[i] = b.pathResult(path)
+	}
+	return &res, nil
+}
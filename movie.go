@@ -0,0 +1,95 @@
+package baconator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// castWeight counts cast members but not movies when measuring pieces of the graph
+func (b *Baconator) castWeight(node graph.Node) int {
+	if b.isCast(node) {
+		return 1
+	}
+	return 0
+}
+
+// resolveMovie finds the movie node matching title. It tries an exact match first, then a normalized
+// match, which ignores case, punctuation and qualifiers like "(film)". When several movies share a
+// normalized title, the one with the largest cast wins.
+func (b *Baconator) resolveMovie(title string) (*nameMatch, error) {
+	b.loadIndexes()
+	if node, ok := b.MovieNodes[title]; ok {
+		return b.newNameMatch(title, node, matchExact, 0), nil
+	}
+	key := normalizeName(title)
+	if key == "" {
+		return nil, &unknownMovieError{title: title}
+	}
+	entries := b.prefixes.entries
+	start := sort.Search(len(entries), func(i int) bool {
+		return entries[i].key() >= key
+	})
+	var nodes []graph.Node
+	for i := start; i < len(entries) && strings.HasPrefix(entries[i].key(), key); i++ {
+		entry := &entries[i]
+		if entry.pos == 0 && entry.name == key && b.NodeInfo[entry.node].Type == movieNode {
+			nodes = append(nodes, entry.node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, &unknownMovieError{title: title}
+	}
+	b.sortByDegree(nodes)
+	return b.newNameMatch(title, nodes[0], matchNormalized, 0), nil
+}
+
+type movieCastMember struct {
+	Name string `json:"name"`
+
+	// Degree is the number of movies the cast member is in
+	Degree int `json:"degree"`
+}
+
+type movieResult struct {
+	Resolved    *nameMatch         `json:"resolved"`
+	Year        int                `json:"year"`
+	Cast        []*movieCastMember `json:"cast"`
+	Component   int                `json:"component"`
+	PageRank    float64            `json:"pagerank,omitempty"`
+	Betweenness float64            `json:"betweenness,omitempty"`
+
+	// Bridge is whether removing the movie would split its connected component. StrandedCast is the
+	// number of cast members who would then be cut off from the largest remaining piece. They can only
+	// be linked to the rest of the component through this movie.
+	Bridge       bool `json:"bridge"`
+	StrandedCast int  `json:"stranded_cast"`
+}
+
+// movie builds the profile of the movie at node
+func (b *Baconator) movie(node graph.Node) *movieResult {
+	b.loadIndexes()
+	info := b.NodeInfo[node]
+	result := movieResult{
+		Cast:         []*movieCastMember{},
+		Component:    b.Graph.Components().Component(node),
+		PageRank:     info.PageRank,
+		Betweenness:  info.Betweenness,
+		Bridge:       b.cuts.IsArticulation(node),
+		StrandedCast: b.cuts.Stranded(node),
+	}
+	if m := b.Movies[info.Name]; m != nil {
+		result.Year = m.Year
+	}
+	for _, member := range b.Graph.NodeNeighbors(node) {
+		result.Cast = append(result.Cast, &movieCastMember{
+			Name:   b.NodeInfo[member].Name,
+			Degree: b.degree(member),
+		})
+	}
+	sort.Slice(result.Cast, func(i, j int) bool {
+		return result.Cast[i].Name < result.Cast[j].Name
+	})
+	return &result
+}
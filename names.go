@@ -0,0 +1,384 @@
+package baconator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// match methods reported in nameMatch.Method
+const (
+	matchExact      = "exact"
+	matchNormalized = "normalized"
+	matchFuzzy      = "fuzzy"
+)
+
+// nameMatch describes how a user supplied name was resolved to a node
+type nameMatch struct {
+	Node     graph.Node `json:"-"`
+	Query    string     `json:"query"`
+	Name     string     `json:"name"`
+	Method   string     `json:"match"`
+	Distance int        `json:"distance,omitempty"`
+}
+
+type unknownCastError struct {
+	name        string
+	suggestions []string
+}
+
+func (e *unknownCastError) Error() string {
+	return fmt.Sprintf("unknown cast member: %q", e.name)
+}
+
+// nameIndex resolves loosely spelled names to cast nodes
+type nameIndex struct {
+	// normalized maps a normalized name to the cast nodes that share it
+	normalized map[string][]graph.Node
+
+	// byLength holds the keys in normalized indexed by their length in bytes. Each list is sorted.
+	byLength [][]string
+}
+
+func (b *Baconator) buildNameIndex() *nameIndex {
+	idx := nameIndex{
+		normalized: make(map[string][]graph.Node, len(b.CastNodes)),
+	}
+	for name, node := range b.CastNodes {
+		key := normalizeName(name)
+		if key == "" {
+			continue
+		}
+		idx.normalized[key] = append(idx.normalized[key], node)
+	}
+	for key, nodes := range idx.normalized {
+		for len(idx.byLength) <= len(key) {
+			idx.byLength = append(idx.byLength, nil)
+		}
+		idx.byLength[len(key)] = append(idx.byLength[len(key)], key)
+		b.sortByDegree(nodes)
+	}
+	for _, keys := range idx.byLength {
+		sort.Strings(keys)
+	}
+	return &idx
+}
+
+// sortByDegree sorts nodes with the most connected first and falls back to name.
+func (b *Baconator) sortByDegree(nodes []graph.Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		di, dj := b.degree(nodes[i]), b.degree(nodes[j])
+		if di != dj {
+			return di > dj
+		}
+		return b.NodeInfo[nodes[i]].Name < b.NodeInfo[nodes[j]].Name
+	})
+}
+
+func (b *Baconator) degree(node graph.Node) int {
+	return len(b.Graph.NodeNeighbors(node))
+}
+
+// resolveCast finds the cast node best matching name. It tries an exact match
+// first, then a normalized match and finally an edit distance match.
+func (b *Baconator) resolveCast(name string) (*nameMatch, error) {
+	b.loadIndexes()
+	if match, ok := b.resolveCastStrictly(name); ok {
+		return match, nil
+	}
+	key := normalizeName(name)
+	if key == "" {
+		return nil, &unknownCastError{name: name}
+	}
+	// the closest suggestion is the fuzzy match when it is close enough, so one search finds both
+	candidates := b.names.closest(key, maxSuggestions, suggestionThreshold(key))
+	if len(candidates) == 0 || candidates[0].distance > fuzzyThreshold(key) {
+		return nil, &unknownCastError{
+			name:        name,
+			suggestions: b.candidateNames(candidates, maxSuggestions),
+		}
+	}
+	best := candidates[0]
+	node := b.names.normalized[best.key][0]
+	return b.newNameMatch(name, node, matchFuzzy, best.distance), nil
+}
+
+// resolveCastStrictly is resolveCast without the edit distance match, so a
+// misspelled name never resolves to somebody else.
+func (b *Baconator) resolveCastStrictly(name string) (*nameMatch, bool) {
+	b.loadIndexes()
+	if node, ok := b.CastNodes[name]; ok {
+		return b.newNameMatch(name, node, matchExact, 0), true
+	}
+	key := normalizeName(name)
+	if nodes := b.names.normalized[key]; key != "" && len(nodes) > 0 {
+		return b.newNameMatch(name, nodes[0], matchNormalized, 0), true
+	}
+	return nil, false
+}
+
+// unknownCast returns the error for a name that didn't resolve, suggesting the closest cast members
+func (b *Baconator) unknownCast(name string) *unknownCastError {
+	err := unknownCastError{name: name}
+	if key := normalizeName(name); key != "" {
+		err.suggestions = b.suggestCast(key, maxSuggestions)
+	}
+	return &err
+}
+
+// CheckCastMember returns an error unless name is a cast member's name. Case,
+// diacritics, punctuation and parenthetical qualifiers are ignored, but unlike
+// the API it doesn't accept misspellings.
+func (b *Baconator) CheckCastMember(name string) error {
+	if _, ok := b.resolveCastStrictly(name); ok {
+		return nil
+	}
+	err := fmt.Errorf("unknown cast member: %q", name)
+	if key := normalizeName(name); key != "" {
+		if suggestions := b.suggestCast(key, maxSuggestions); len(suggestions) > 0 {
+			err = fmt.Errorf("%v. did you mean %q?", err, suggestions[0])
+		}
+	}
+	return err
+}
+
+func (b *Baconator) newNameMatch(query string, node graph.Node, method string, distance int) *nameMatch {
+	return &nameMatch{
+		Node:     node,
+		Query:    query,
+		Name:     b.NodeInfo[node].Name,
+		Method:   method,
+		Distance: distance,
+	}
+}
+
+// fuzzyThreshold is the largest edit distance accepted when resolving key
+func fuzzyThreshold(key string) int {
+	const maxThreshold = 3
+	threshold := len(key) / 4
+	if threshold > maxThreshold {
+		threshold = maxThreshold
+	}
+	return threshold
+}
+
+// suggestionThreshold is the largest edit distance for "did you mean" suggestions
+func suggestionThreshold(key string) int {
+	const (
+		minThreshold = 2
+		maxThreshold = 8
+	)
+	threshold := len(key) / 2
+	if threshold < minThreshold {
+		threshold = minThreshold
+	}
+	if threshold > maxThreshold {
+		threshold = maxThreshold
+	}
+	return threshold
+}
+
+const maxSuggestions = 5
+
+// suggestCast returns the names of up to n cast members closest to key
+func (b *Baconator) suggestCast(key string, n int) []string {
+	b.loadIndexes()
+	return b.candidateNames(b.names.closest(key, n, suggestionThreshold(key)), n)
+}
+
+// candidateNames returns the names of up to n cast members with the keys of candidates
+func (b *Baconator) candidateNames(candidates []nameCandidate, n int) []string {
+	suggestions := make([]string, 0, n)
+	for _, candidate := range candidates {
+		for _, node := range b.names.normalized[candidate.key] {
+			if len(suggestions) == n {
+				return suggestions
+			}
+			suggestions = append(suggestions, b.NodeInfo[node].Name)
+		}
+	}
+	return suggestions
+}
+
+type nameCandidate struct {
+	key      string
+	distance int
+}
+
+// closest returns up to n keys within maxDistance edits of key ordered by
+// distance and then by key.
+//  The edit distance is at least the difference in length, so only keys with
+//  a length within maxDistance of key's are compared. The nearest lengths are
+//  compared first so that maxDistance shrinks as early as possible.
+func (idx *nameIndex) closest(key string, n, maxDistance int) []nameCandidate {
+	if n < 1 || maxDistance < 1 {
+		return nil
+	}
+	result := make([]nameCandidate, 0, n+1)
+	scratch := make([]int, 2*(len(key)+1))
+	for lenDiff := 0; lenDiff <= maxDistance; lenDiff++ {
+		lengths := []int{len(key) - lenDiff, len(key) + lenDiff}
+		if lenDiff == 0 {
+			lengths = lengths[:1]
+		}
+		for _, length := range lengths {
+			if length < 0 || length >= len(idx.byLength) {
+				continue
+			}
+			for _, candidate := range idx.byLength[length] {
+				dist := editDistance(key, candidate, maxDistance, scratch)
+				if dist > maxDistance {
+					continue
+				}
+				result = insertCandidate(result, nameCandidate{key: candidate, distance: dist}, n)
+				if len(result) == n {
+					// there is no point looking for anything worse than the current worst
+					maxDistance = result[n-1].distance
+				}
+			}
+		}
+	}
+	return result
+}
+
+// insertCandidate adds c to the sorted list keeping at most n items.
+func insertCandidate(list []nameCandidate, c nameCandidate, n int) []nameCandidate {
+	i := sort.Search(len(list), func(i int) bool {
+		if list[i].distance != c.distance {
+			return list[i].distance > c.distance
+		}
+		return list[i].key > c.key
+	})
+	if i >= n {
+		return list
+	}
+	list = append(list, nameCandidate{})
+	copy(list[i+1:], list[i:])
+	list[i] = c
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// editDistance returns the Levenshtein distance between a and b measured in bytes.
+// When the distance is greater than maxDistance it returns maxDistance+1 without
+// finishing the calculation. scratch must have room for 2*(len(a)+1) ints.
+func editDistance(a, b string, maxDistance int, scratch []int) int {
+	prev := scratch[:len(a)+1]
+	cur := scratch[len(a)+1 : 2*(len(a)+1)]
+	for i := range prev {
+		prev[i] = i
+	}
+	for j := 1; j <= len(b); j++ {
+		cur[0] = j
+		rowMin := cur[0]
+		for i := 1; i <= len(a); i++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			val := prev[i-1] + cost
+			if prev[i]+1 < val {
+				val = prev[i] + 1
+			}
+			if cur[i-1]+1 < val {
+				val = cur[i-1] + 1
+			}
+			cur[i] = val
+			if val < rowMin {
+				rowMin = val
+			}
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		prev, cur = cur, prev
+	}
+	if prev[len(a)] > maxDistance {
+		return maxDistance + 1
+	}
+	return prev[len(a)]
+}
+
+// normalizeName lowercases nm, strips diacritics, parenthetical qualifiers
+// and punctuation, and collapses whitespace.
+//  "Kevin Bacon (actor)" -> "kevin bacon"
+//  "Penélope Cruz" -> "penelope cruz"
+func normalizeName(nm string) string {
+	var sb strings.Builder
+	sb.Grow(len(nm))
+	depth := 0
+	pendingSpace := false
+	for _, r := range nm {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+			pendingSpace = true
+			continue
+		case r == ')' || r == ']':
+			if depth > 0 {
+				depth--
+			}
+			pendingSpace = true
+			continue
+		case depth > 0:
+			continue
+		case r == '\'' || r == '’' || r == '`' || r == '.':
+			continue
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		pendingSpace = false
+		r = unicode.ToLower(r)
+		if folded, ok := diacriticFolds[r]; ok {
+			sb.WriteString(folded)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+var diacriticFolds = buildDiacriticFolds(map[string]string{
+	"a":  "àáâãäåāăąǎ",
+	"ae": "æ",
+	"c":  "çćĉċč",
+	"d":  "ďđð",
+	"e":  "èéêëēĕėęě",
+	"g":  "ĝğġģ",
+	"h":  "ĥħ",
+	"i":  "ìíîïĩīĭįı",
+	"j":  "ĵ",
+	"k":  "ķ",
+	"l":  "ĺļľŀł",
+	"n":  "ñńņňŉ",
+	"o":  "òóôõöøōŏőǒ",
+	"oe": "œ",
+	"r":  "ŕŗř",
+	"s":  "śŝşšș",
+	"ss": "ß",
+	"t":  "ţťŧț",
+	"th": "þ",
+	"u":  "ùúûüũūŭůűųǔ",
+	"w":  "ŵ",
+	"y":  "ýÿŷ",
+	"z":  "źżž",
+})
+
+func buildDiacriticFolds(variants map[string]string) map[rune]string {
+	folds := map[rune]string{}
+	for base, runes := range variants {
+		for _, r := range runes {
+			folds[r] = base
+		}
+	}
+	return folds
+}
@@ -0,0 +1,72 @@
+package baconator
+
+import (
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// PageRankScores holds the PageRank of cast members and movies by name: the share of its time a random
+// walk through movies and their casts spends at each one. Zero scores are left out.
+type PageRankScores struct {
+	// Iterations is the number of iterations the scores took to converge
+	Iterations int                `json:"iterations"`
+	Cast       map[string]float64 `json:"cast"`
+	Movies     map[string]float64 `json:"movies"`
+}
+
+// ComputePageRank computes PageRank with the given damping and tolerance, stores the scores in b's node
+// info and returns them. damping is the probability that the walk follows a link instead of jumping to a
+// random node. The walk stops when the scores change by less than tolerance in total. Zero or less
+// means the defaults of 0.85 and 1e-6.
+func (b *Baconator) ComputePageRank(damping, tolerance float64) *PageRankScores {
+	ranks, iterations := b.Graph.PageRank(&graph.PageRankOptions{
+		Damping:   damping,
+		Tolerance: tolerance,
+	})
+	scores := PageRankScores{
+		Iterations: iterations,
+		Cast:       map[string]float64{},
+		Movies:     map[string]float64{},
+	}
+	for i, rank := range ranks {
+		if rank == 0 {
+			continue
+		}
+		info := b.NodeInfo[i]
+		switch info.Type {
+		case castNode:
+			scores.Cast[info.Name] = rank
+		case movieNode:
+			scores.Movies[info.Name] = rank
+		}
+	}
+	b.SetPageRank(&scores)
+	return &scores
+}
+
+// SetPageRank stores scores in b's node info. Cast members and movies that aren't in scores get zero.
+func (b *Baconator) SetPageRank(scores *PageRankScores) {
+	for i := range b.NodeInfo {
+		info := &b.NodeInfo[i]
+		switch info.Type {
+		case castNode:
+			info.PageRank = scores.Cast[info.Name]
+		case movieNode:
+			info.PageRank = scores.Movies[info.Name]
+		}
+	}
+}
+
+// WriteFile writes the scores to filename as json
+func (s *PageRankScores) WriteFile(filename string) error {
+	return writeJSONFile(filename, s)
+}
+
+// LoadPageRankScores reads scores written by PageRankScores.WriteFile
+func LoadPageRankScores(filename string) (*PageRankScores, error) {
+	var scores PageRankScores
+	err := readJSONFile(filename, &scores)
+	if err != nil {
+		return nil, err
+	}
+	return &scores, nil
+}
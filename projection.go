@@ -0,0 +1,23 @@
+package baconator
+
+import (
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// BuildProjection builds a graph that links cast members directly whenever they share a movie. Once it
+// is built, distance queries like center and farthest search it instead of Graph. Each hop between
+// cast members is one level instead of two, so searches are shallower, but the projected graph takes
+// more memory because every pair of cast members in a movie gets its own edge. It must be called
+// before b is shared between goroutines.
+func (b *Baconator) BuildProjection() {
+	b.projection = b.Graph.Project(b.isCast)
+}
+
+// distanceGraph returns the graph that distance queries search and the number of its levels that make
+// up one hop between cast members
+func (b *Baconator) distanceGraph() (*graph.Graph, int) {
+	if b.projection != nil {
+		return b.projection.Graph, 1
+	}
+	return b.Graph, 2
+}
@@ -0,0 +1,55 @@
+package baconator
+
+import (
+	"sort"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// relatedTolerance is the default personalized PageRank tolerance for /related. It is coarser than the
+// graph's default because only the top few scores matter and they settle long before the tail does.
+const relatedTolerance = 1e-5
+
+type relatedCast struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type relatedResult struct {
+	Resolved *nameMatch     `json:"resolved"`
+	Related  []*relatedCast `json:"related"`
+}
+
+// related finds the limit cast members most strongly associated with node by personalized PageRank.
+// Cast members who share more movies with node, and with node's other co-stars, score higher. Nil opts
+// uses relatedTolerance.
+func (b *Baconator) related(node graph.Node, limit int, opts *graph.PageRankOptions) *relatedResult {
+	if opts == nil {
+		opts = &graph.PageRankOptions{
+			Tolerance: relatedTolerance,
+		}
+	}
+	scores := b.Graph.PersonalizedPageRank(node, opts)
+	related := make([]*relatedCast, 0, len(scores))
+	for n, score := range scores {
+		if n == node || b.NodeInfo[n].Type != castNode {
+			continue
+		}
+		related = append(related, &relatedCast{
+			Name:  b.NodeInfo[n].Name,
+			Score: score,
+		})
+	}
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Name < related[j].Name
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return &relatedResult{
+		Related: related,
+	}
+}
@@ -0,0 +1,198 @@
+package baconator
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// searchEntry is one word-aligned suffix of a normalized node name
+type searchEntry struct {
+	name string // the node's normalized name
+	pos  int    // where the suffix starts in name
+	node graph.Node
+}
+
+func (e *searchEntry) key() string {
+	return e.name[e.pos:]
+}
+
+// searchIndex is a sorted array of every word-aligned suffix of cast and movie
+// names. It finds prefix matches with a binary search.
+type searchIndex struct {
+	entries []searchEntry
+}
+
+func (b *Baconator) buildSearchIndex() *searchIndex {
+	var idx searchIndex
+	idx.entries = make([]searchEntry, 0, len(b.NodeInfo)*2)
+	for _, info := range b.NodeInfo {
+		name := normalizeName(info.Name)
+		for pos := 0; pos < len(name); pos++ {
+			if pos > 0 && name[pos-1] != ' ' {
+				continue
+			}
+			idx.entries = append(idx.entries, searchEntry{
+				name: name,
+				pos:  pos,
+				node: info.Node,
+			})
+		}
+	}
+	sort.Slice(idx.entries, func(i, j int) bool {
+		ki, kj := idx.entries[i].key(), idx.entries[j].key()
+		if ki != kj {
+			return ki < kj
+		}
+		return idx.entries[i].node < idx.entries[j].node
+	})
+	return &idx
+}
+
+type searchOptions struct {
+	nodeType nodeType // zero matches all types
+	limit    int
+	offset   int
+}
+
+type searchHit struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Degree int    `json:"degree"`
+
+	// PageRank and Betweenness are only set when their scores are loaded
+	PageRank    float64 `json:"pagerank,omitempty"`
+	Betweenness float64 `json:"betweenness,omitempty"`
+
+	node    graph.Node
+	atStart bool
+}
+
+type searchResult struct {
+	Query   string       `json:"query"`
+	Total   int          `json:"total"`
+	Results []*searchHit `json:"results"`
+}
+
+// search finds cast members and movies with a word starting with query. Names
+// that start with query rank first, then names with higher degree.
+func (b *Baconator) search(query string, opts *searchOptions) *searchResult {
+	b.loadIndexes()
+	result := searchResult{
+		Query:   query,
+		Results: []*searchHit{},
+	}
+	prefix := normalizeName(query)
+	if prefix == "" || opts.limit < 1 {
+		return &result
+	}
+	entries := b.prefixes.entries
+	start := sort.Search(len(entries), func(i int) bool {
+		return entries[i].key() >= prefix
+	})
+	hits := searchHeap{
+		limit: opts.offset + opts.limit,
+	}
+	for i := start; i < len(entries) && strings.HasPrefix(entries[i].key(), prefix); i++ {
+		entry := &entries[i]
+		if opts.nodeType != 0 && b.NodeInfo[entry.node].Type != opts.nodeType {
+			continue
+		}
+		if matchesEarlierWord(entry, prefix) {
+			continue
+		}
+		result.Total++
+		hits.offer(b, entry)
+	}
+	ranked := hits.sorted()
+	if opts.offset < len(ranked) {
+		result.Results = ranked[opts.offset:]
+	}
+	return &result
+}
+
+// matchesEarlierWord checks whether a word before entry's suffix also matches
+// prefix so that every node is only counted once.
+func matchesEarlierWord(entry *searchEntry, prefix string) bool {
+	for pos := 0; pos < entry.pos; pos++ {
+		if pos > 0 && entry.name[pos-1] != ' ' {
+			continue
+		}
+		if strings.HasPrefix(entry.name[pos:], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchHeap keeps the best limit hits seen. The worst hit is at the root.
+type searchHeap struct {
+	limit int
+	hits  []*searchHit
+}
+
+func (h *searchHeap) offer(b *Baconator, entry *searchEntry) {
+	hit := searchHit{
+		Name:    b.NodeInfo[entry.node].Name,
+		Type:    b.NodeInfo[entry.node].Type.String(),
+		Degree:  b.degree(entry.node),
+		node:    entry.node,
+		atStart: entry.pos == 0,
+
+		PageRank:    b.NodeInfo[entry.node].PageRank,
+		Betweenness: b.NodeInfo[entry.node].Betweenness,
+	}
+	if len(h.hits) < h.limit {
+		heap.Push(h, hit.clone())
+		return
+	}
+	if !hit.betterThan(h.hits[0]) {
+		return
+	}
+	h.hits[0] = hit.clone()
+	heap.Fix(h, 0)
+}
+
+// sorted empties h and returns its hits best first
+func (h *searchHeap) sorted() []*searchHit {
+	result := make([]*searchHit, len(h.hits))
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(h).(*searchHit)
+	}
+	return result
+}
+
+func (h *searchHeap) Len() int { return len(h.hits) }
+
+func (h *searchHeap) Less(i, j int) bool { return h.hits[j].betterThan(h.hits[i]) }
+
+func (h *searchHeap) Swap(i, j int) { h.hits[i], h.hits[j] = h.hits[j], h.hits[i] }
+
+func (h *searchHeap) Push(x interface{}) { h.hits = append(h.hits, x.(*searchHit)) }
+
+func (h *searchHeap) Pop() interface{} {
+	last := h.hits[len(h.hits)-1]
+	h.hits = h.hits[:len(h.hits)-1]
+	return last
+}
+
+// clone returns a heap allocated copy of h so that rejected hits never leave the stack
+func (h *searchHit) clone() *searchHit {
+	c := *h
+	return &c
+}
+
+func (h *searchHit) betterThan(other *searchHit) bool {
+	if h.atStart != other.atStart {
+		return h.atStart
+	}
+	if h.Degree != other.Degree {
+		return h.Degree > other.Degree
+	}
+	if h.Name != other.Name {
+		return h.Name < other.Name
+	}
+	return h.node < other.node
+}
@@ -2,74 +2,539 @@ package baconator
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"github.com/willabides/baconator/internal/graph"
 )
 
 // Server is an http server for baconator
 type Server struct {
 	baconator *Baconator
+	centers   *centerCache
+	ranking   *CenterRanking
+
+	// relatedOptions configures the personalized PageRank behind /related
+	relatedOptions graph.PageRankOptions
+
+	// defaultCenter is who /actor reports Bacon numbers relative to. It is nil when the name given to
+	// WithDefaultCenter isn't a cast member's name.
+	defaultCenter *nameMatch
+}
+
+// ServerOption configures a Server
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	centerCacheSize int
+	ranking         *CenterRanking
+	defaultCenter   string
+	related         graph.PageRankOptions
+}
+
+// WithCenterCacheSize sets the number of /center results the server keeps cached. Zero disables the
+// cache except for centers added with Server.PrecomputeCenters. The default is 1000.
+func WithCenterCacheSize(size int) ServerOption {
+	return func(o *serverOptions) {
+		o.centerCacheSize = size
+	}
+}
+
+// WithCenterRanking serves ranking at /centers/top
+func WithCenterRanking(ranking *CenterRanking) ServerOption {
+	return func(o *serverOptions) {
+		o.ranking = ranking
+	}
+}
+
+// WithDefaultCenter sets the cast member that /actor reports Bacon numbers relative to. The default is
+// DefaultCenter. name isn't corrected for misspellings, and /actor leaves Bacon numbers out when it
+// isn't a cast member's name. Check it with Baconator.CheckCastMember first.
+func WithDefaultCenter(name string) ServerOption {
+	return func(o *serverOptions) {
+		o.defaultCenter = name
+	}
+}
+
+// WithRelatedPageRank sets the damping and tolerance of the personalized PageRank that scores /related
+// results. damping is the probability that the walk follows a link instead of jumping back to the actor.
+// Lower tolerance gives more precise scores but takes longer. Zero or less keeps the defaults of 0.85 and
+// 1e-5.
+func WithRelatedPageRank(damping, tolerance float64) ServerOption {
+	return func(o *serverOptions) {
+		if damping > 0 {
+			o.related.Damping = damping
+		}
+		if tolerance > 0 {
+			o.related.Tolerance = tolerance
+		}
+	}
 }
 
 // NewServer returns a new Server
-func NewServer(baconator *Baconator) *Server {
-	return &Server{
-		baconator: baconator,
+func NewServer(baconator *Baconator, opts ...ServerOption) *Server {
+	o := serverOptions{
+		centerCacheSize: defaultCenterCacheSize,
+		defaultCenter:   DefaultCenter,
+		related: graph.PageRankOptions{
+			Tolerance: relatedTolerance,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// a gob decoded Baconator would otherwise build its indexes during the first request
+	baconator.loadIndexes()
+	s := Server{
+		baconator:      baconator,
+		centers:        newCenterCache(o.centerCacheSize),
+		ranking:        o.ranking,
+		relatedOptions: o.related,
 	}
+	if match, ok := baconator.resolveCastStrictly(o.defaultCenter); ok {
+		s.defaultCenter = match
+	}
+	return &s
 }
 
-func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	if req.Method != http.MethodGet {
-		http.Error(w, "", http.StatusNotFound)
-		return
+// PrecomputeCenters calculates the /center results for names and keeps them cached for as long as
+// the server runs. Like CheckCastMember, it doesn't accept misspellings.
+func (s *Server) PrecomputeCenters(names ...string) error {
+	for _, name := range names {
+		match, ok := s.baconator.resolveCastStrictly(name)
+		if !ok {
+			return s.baconator.unknownCast(name)
+		}
+		node := match.Node
+		s.centers.pinComputed(node, func() *centerResult {
+			return s.baconator.center(node)
+		})
 	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var handler func(w http.ResponseWriter, req *http.Request) error
 	switch req.URL.Path {
 	case "/center":
-		s.center(w, req)
+		handler = s.center
 	case "/link":
-		s.link(w, req)
+		handler = s.link
+	case "/links/all":
+		handler = s.allLinks
+	case "/search", "/autocomplete":
+		handler = s.search
+	case "/centers/top":
+		handler = s.topCenters
+	case "/component":
+		handler = s.component
+	case "/farthest":
+		handler = s.farthest
+	case "/related":
+		handler = s.related
+	case "/costars":
+		handler = s.costars
+	case "/actor":
+		handler = s.actor
+	case "/movie":
+		handler = s.movie
+	case "/stats":
+		handler = s.stats
 	default:
-		http.Error(w, "", http.StatusNotFound)
-	}
-}
-
-func (s *Server) link(w http.ResponseWriter, req *http.Request) {
-	query := req.URL.Query()
-	src := query.Get("a")
-	if src == "" {
-		http.Error(w, "a is a required query parameter", http.StatusBadRequest)
+		writeError(w, &apiError{
+			Status:  http.StatusNotFound,
+			Code:    codeNotFound,
+			Message: "no such endpoint: " + req.URL.Path,
+		})
 		return
 	}
-	dest := query.Get("b")
-	if dest == "" {
-		http.Error(w, "b is a required query parameter", http.StatusBadRequest)
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, &apiError{
+			Status:  http.StatusMethodNotAllowed,
+			Code:    codeMethodNotAllowed,
+			Message: "method not allowed: " + req.Method,
+		})
 		return
 	}
-	res, err := s.baconator.links(src, dest)
+	err := handler(w, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		writeError(w, err)
 	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
 	w.Header().Add("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(res)
+	err := json.NewEncoder(w).Encode(v)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func (s *Server) center(w http.ResponseWriter, req *http.Request) {
-	p := req.URL.Query().Get("p")
-	if p == "" {
-		http.Error(w, "p is a required query parameter", http.StatusBadRequest)
-		return
+// maxYear is the largest year accepted by year parameters
+const maxYear = 9999
+
+// linkParams parses the query parameters shared by the link endpoints
+func linkParams(req *http.Request) (src, dest string, opts *linkOptions, err error) {
+	src, err = requiredParam(req, "a")
+	if err != nil {
+		return "", "", nil, err
 	}
-	res := s.baconator.center(s.baconator.CastNodes[p])
-	if res == nil {
-		http.Error(w, "person not found", http.StatusNotFound)
-		return
+	dest, err = requiredParam(req, "b")
+	if err != nil {
+		return "", "", nil, err
 	}
-	w.Header().Add("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(res)
+	query := req.URL.Query()
+	opts = &linkOptions{
+		excludeCast:   query["exclude_cast"],
+		excludeMovies: query["exclude_movie"],
+		strategy:      query.Get("prefer"),
+	}
+	opts.maxHops, err = intParam(req, "max_hops", defaultMaxHops, 1, defaultMaxHops)
 	if err != nil {
-		panic(err)
+		return "", "", nil, err
+	}
+	opts.fromYear, err = intParam(req, "from_year", 0, 0, maxYear)
+	if err != nil {
+		return "", "", nil, err
+	}
+	opts.toYear, err = intParam(req, "to_year", 0, 0, maxYear)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if opts.toYear != 0 && opts.fromYear > opts.toYear {
+		return "", "", nil, invalidParamError("to_year", "to_year must not be before from_year")
+	}
+	return src, dest, opts, nil
+}
+
+func (s *Server) link(w http.ResponseWriter, req *http.Request) error {
+	const maxK = 20
+	src, dest, opts, err := linkParams(req)
+	if err != nil {
+		return err
+	}
+	opts.k, err = intParam(req, "k", 1, 1, maxK)
+	if err != nil {
+		return err
+	}
+	switch req.URL.Query().Get("mode") {
+	case "", "hops":
+	case "weighted":
+		if opts.k > 1 {
+			return invalidParamError("k", "k is not supported when mode is weighted")
+		}
+		if req.URL.Query().Get("max_hops") != "" {
+			return invalidParamError("max_hops", "max_hops is not supported when mode is weighted")
+		}
+		opts.weighted = true
+		opts.cost = req.URL.Query().Get("cost")
+	default:
+		return invalidParamError("mode", "mode must be hops or weighted")
+	}
+	if !opts.weighted && req.URL.Query().Get("cost") != "" {
+		return invalidParamError("cost", "cost is only supported when mode is weighted")
+	}
+	res, err := s.baconator.links(src, dest, opts)
+	if err != nil {
+		return linkError(err, src, dest)
+	}
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) allLinks(w http.ResponseWriter, req *http.Request) error {
+	const (
+		defaultLimit = 10
+		maxLimit     = 100
+	)
+	src, dest, opts, err := linkParams(req)
+	if err != nil {
+		return err
+	}
+	// every shortest link is counted, so there is nothing for a strategy to prefer
+	if opts.strategy != "" {
+		return invalidParamError("prefer", "prefer is not supported by /links/all")
+	}
+	limit, err := intParam(req, "limit", defaultLimit, 0, maxLimit)
+	if err != nil {
+		return err
+	}
+	res, err := s.baconator.allLinks(src, dest, limit, opts)
+	if err != nil {
+		return linkError(err, src, dest)
+	}
+	writeJSON(w, res)
+	return nil
+}
+
+// linkError converts an error from Baconator.links to an apiError
+func linkError(err error, src, dest string) error {
+	var pathErr *noPathError
+	if errors.As(err, &pathErr) {
+		if pathErr.maxHops > 0 {
+			return &apiError{
+				Status:  http.StatusUnprocessableEntity,
+				Code:    codeMaxHopsExceeded,
+				Message: err.Error(),
+				Param:   "max_hops",
+			}
+		}
+		return &apiError{
+			Status:  http.StatusNotFound,
+			Code:    codeNoPath,
+			Message: err.Error(),
+		}
+	}
+	var movieErr *unknownMovieError
+	if errors.As(err, &movieErr) {
+		return movieError(err, "exclude_movie")
+	}
+	var strategyErr *unknownStrategyError
+	if errors.As(err, &strategyErr) {
+		return invalidParamError("prefer", err.Error())
+	}
+	var costErr *unknownCostError
+	if errors.As(err, &costErr) {
+		return invalidParamError("cost", err.Error())
+	}
+	var projectionErr *noProjectionError
+	if errors.As(err, &projectionErr) {
+		return invalidParamError("cost", err.Error()+". start the server with -projected")
+	}
+	var optionErr *unsupportedCostOptionError
+	if errors.As(err, &optionErr) {
+		return invalidParamError(optionErr.param, err.Error())
+	}
+	var endpointErr *excludedEndpointError
+	if errors.As(err, &endpointErr) {
+		return invalidParamError("exclude_cast", err.Error())
+	}
+	var castErr *unknownCastError
+	if !errors.As(err, &castErr) {
+		return err
+	}
+	switch castErr.name {
+	case src:
+		return castError(err, "a")
+	case dest:
+		return castError(err, "b")
+	default:
+		return castError(err, "exclude_cast")
+	}
+}
+
+func (s *Server) center(w http.ResponseWriter, req *http.Request) error {
+	p, err := requiredParam(req, "p")
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveCast(p)
+	if err != nil {
+		return castError(err, "p")
+	}
+	res := s.centers.getOrCompute(match.Node, func() *centerResult {
+		return s.baconator.center(match.Node)
+	})
+	writeJSON(w, struct {
+		*centerResult
+		Resolved *nameMatch `json:"resolved"`
+	}{
+		centerResult: res,
+		Resolved:     match,
+	})
+	return nil
+}
+
+type topCentersResult struct {
+	ComponentSize int             `json:"component_size"`
+	Centers       []*RankedCenter `json:"centers"`
+}
+
+func (s *Server) topCenters(w http.ResponseWriter, req *http.Request) error {
+	const (
+		defaultN = 10
+		maxN     = 1000
+	)
+	if s.ranking == nil {
+		return &apiError{
+			Status:  http.StatusServiceUnavailable,
+			Code:    codeUnavailable,
+			Message: "no center ranking is loaded",
+		}
+	}
+	n, err := intParam(req, "n", defaultN, 1, maxN)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, &topCentersResult{
+		ComponentSize: s.ranking.ComponentSize,
+		Centers:       s.ranking.top(n),
+	})
+	return nil
+}
+
+type statsResult struct {
+	Graph       *GraphStats      `json:"graph"`
+	CenterCache centerCacheStats `json:"center_cache"`
+}
+
+func (s *Server) stats(w http.ResponseWriter, _ *http.Request) error {
+	writeJSON(w, &statsResult{
+		Graph:       s.baconator.Stats(),
+		CenterCache: s.centers.statsSnapshot(),
+	})
+	return nil
+}
+
+func (s *Server) farthest(w http.ResponseWriter, req *http.Request) error {
+	const (
+		defaultLimit = 100
+		maxLimit     = 1000
+	)
+	p, err := requiredParam(req, "p")
+	if err != nil {
+		return err
+	}
+	limit, err := intParam(req, "limit", defaultLimit, 1, maxLimit)
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveCast(p)
+	if err != nil {
+		return castError(err, "p")
+	}
+	res := s.baconator.farthest(match.Node, limit)
+	res.Resolved = match
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) related(w http.ResponseWriter, req *http.Request) error {
+	const (
+		defaultLimit = 10
+		maxLimit     = 100
+	)
+	p, err := requiredParam(req, "p")
+	if err != nil {
+		return err
+	}
+	limit, err := intParam(req, "limit", defaultLimit, 1, maxLimit)
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveCast(p)
+	if err != nil {
+		return castError(err, "p")
+	}
+	res := s.baconator.related(match.Node, limit, &s.relatedOptions)
+	res.Resolved = match
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) costars(w http.ResponseWriter, req *http.Request) error {
+	const (
+		defaultLimit = 100
+		maxLimit     = 1000
+	)
+	p, err := requiredParam(req, "p")
+	if err != nil {
+		return err
+	}
+	limit, err := intParam(req, "limit", defaultLimit, 1, maxLimit)
+	if err != nil {
+		return err
+	}
+	offset, err := intParam(req, "offset", 0, 0, -1)
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveCast(p)
+	if err != nil {
+		return castError(err, "p")
+	}
+	res := s.baconator.costars(match.Node, limit, offset)
+	res.Resolved = match
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) actor(w http.ResponseWriter, req *http.Request) error {
+	p, err := requiredParam(req, "p")
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveCast(p)
+	if err != nil {
+		return castError(err, "p")
+	}
+	res := s.baconator.actor(match.Node, s.defaultCenter)
+	res.Resolved = match
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) movie(w http.ResponseWriter, req *http.Request) error {
+	t, err := requiredParam(req, "t")
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveMovie(t)
+	if err != nil {
+		return movieError(err, "t")
+	}
+	res := s.baconator.movie(match.Node)
+	res.Resolved = match
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) component(w http.ResponseWriter, req *http.Request) error {
+	p, err := requiredParam(req, "p")
+	if err != nil {
+		return err
+	}
+	match, err := s.baconator.resolveCast(p)
+	if err != nil {
+		return castError(err, "p")
+	}
+	res := s.baconator.component(match.Node)
+	res.Resolved = match
+	writeJSON(w, res)
+	return nil
+}
+
+func (s *Server) search(w http.ResponseWriter, req *http.Request) error {
+	const (
+		defaultLimit = 10
+		maxLimit     = 100
+
+		// maxOffset bounds the hits search keeps to rank a page
+		maxOffset = 10000
+	)
+	q, err := requiredParam(req, "q")
+	if err != nil {
+		return err
+	}
+	opts := searchOptions{}
+	switch req.URL.Query().Get("type") {
+	case "":
+	case castNode.String():
+		opts.nodeType = castNode
+	case movieNode.String():
+		opts.nodeType = movieNode
+	default:
+		return invalidParamError("type", "type must be cast or movie")
+	}
+	opts.limit, err = intParam(req, "limit", defaultLimit, 1, maxLimit)
+	if err != nil {
+		return err
+	}
+	opts.offset, err = intParam(req, "offset", 0, 0, maxOffset)
+	if err != nil {
+		return err
 	}
+	writeJSON(w, s.baconator.search(q, &opts))
+	return nil
 }
@@ -1,12 +1,18 @@
 package baconator
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -31,3 +37,404 @@ func TestServer_link(t *testing.T) {
 	_, err := http.Get(u)
 	require.NoError(t, err)
 }
+
+func TestServer_link_resolution(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got linkResult
+	status := getJSON(t, server.URL+"/link?a=kevin+bacon&b=Penelope+Cruz", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Kevin Bacon", got.A.Name)
+	require.Equal(t, matchNormalized, got.A.Method)
+	require.Equal(t, "Penélope Cruz", got.B.Name)
+	require.Len(t, got.Path, 5)
+}
+
+func TestServer_link_decoded(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(newFixtureBaconator(t)))
+	var decoded Baconator
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	server := httptest.NewServer(NewServer(&decoded))
+	var got linkResult
+	status := getJSON(t, server.URL+"/link?a=kevin+bacon&b=Tom+Cruise&prefer=newest", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Kevin Bacon", got.A.Name)
+	require.Len(t, got.Path, 3)
+}
+
+func TestServer_center_suggestions(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got errorResponse
+	status := getJSON(t, server.URL+"/center?p=Tom+Crooz", &got)
+	require.Equal(t, http.StatusNotFound, status)
+	require.Equal(t, &apiError{
+		Code:        codeUnknownCast,
+		Message:     `unknown cast member: "Tom Crooz"`,
+		Param:       "p",
+		Suggestions: []string{"Tom Cruise"},
+	}, got.Error)
+}
+
+func TestServer_errors(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	for _, td := range []struct {
+		method, path string
+		status       int
+		code, param  string
+	}{
+		{method: http.MethodPost, path: "/link?a=Tom+Cruise&b=Tom+Hanks", status: http.StatusMethodNotAllowed, code: codeMethodNotAllowed},
+		{method: http.MethodGet, path: "/nope", status: http.StatusNotFound, code: codeNotFound},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise", status: http.StatusBadRequest, code: codeMissingParam, param: "b"},
+		{method: http.MethodGet, path: "/link?a=Nobody+Here&b=Tom+Cruise", status: http.StatusNotFound, code: codeUnknownCast, param: "a"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Other+Loner", status: http.StatusNotFound, code: codeNoPath},
+		{method: http.MethodGet, path: "/link?a=Tom+Hanks&b=Harrison+Ford&max_hops=2", status: http.StatusUnprocessableEntity, code: codeMaxHopsExceeded, param: "max_hops"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&exclude_cast=Nobody+Here", status: http.StatusNotFound, code: codeUnknownCast, param: "exclude_cast"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&exclude_cast=Tom+Hanks", status: http.StatusBadRequest, code: codeInvalidParam, param: "exclude_cast"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&exclude_movie=Nope", status: http.StatusNotFound, code: codeUnknownMovie, param: "exclude_movie"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&from_year=2000&to_year=1990", status: http.StatusBadRequest, code: codeInvalidParam, param: "to_year"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=cheapest", status: http.StatusBadRequest, code: codeInvalidParam, param: "mode"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=weighted&k=2", status: http.StatusBadRequest, code: codeInvalidParam, param: "k"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=weighted&max_hops=3", status: http.StatusBadRequest, code: codeInvalidParam, param: "max_hops"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&cost=recency", status: http.StatusBadRequest, code: codeInvalidParam, param: "cost"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=weighted&cost=nope", status: http.StatusBadRequest, code: codeInvalidParam, param: "cost"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=weighted&cost=collaboration", status: http.StatusBadRequest, code: codeInvalidParam, param: "cost"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=weighted&cost=collaboration&exclude_movie=Top+Gun", status: http.StatusBadRequest, code: codeInvalidParam, param: "exclude_movie"},
+		{method: http.MethodGet, path: "/link?a=Tom+Cruise&b=Tom+Hanks&mode=weighted&cost=collaboration&to_year=2000", status: http.StatusBadRequest, code: codeInvalidParam, param: "to_year"},
+		{method: http.MethodGet, path: "/links/all?a=Tom+Cruise&b=Tom+Hanks&prefer=bogus", status: http.StatusBadRequest, code: codeInvalidParam, param: "prefer"},
+		{method: http.MethodGet, path: "/centers/top", status: http.StatusServiceUnavailable, code: codeUnavailable},
+		{method: http.MethodGet, path: "/component", status: http.StatusBadRequest, code: codeMissingParam, param: "p"},
+		{method: http.MethodGet, path: "/component?p=Nobody+Here", status: http.StatusNotFound, code: codeUnknownCast, param: "p"},
+		{method: http.MethodGet, path: "/farthest?p=Kevin+Bacon&limit=0", status: http.StatusBadRequest, code: codeInvalidParam, param: "limit"},
+		{method: http.MethodGet, path: "/related?p=Kevin+Bacon&limit=101", status: http.StatusBadRequest, code: codeInvalidParam, param: "limit"},
+		{method: http.MethodGet, path: "/costars?p=Kevin+Bacon&offset=-1", status: http.StatusBadRequest, code: codeInvalidParam, param: "offset"},
+		{method: http.MethodGet, path: "/actor?p=Nobody+Here", status: http.StatusNotFound, code: codeUnknownCast, param: "p"},
+		{method: http.MethodGet, path: "/movie", status: http.StatusBadRequest, code: codeMissingParam, param: "t"},
+		{method: http.MethodGet, path: "/movie?t=Nope", status: http.StatusNotFound, code: codeUnknownMovie, param: "t"},
+		{method: http.MethodGet, path: "/search?q=tom&type=dog", status: http.StatusBadRequest, code: codeInvalidParam, param: "type"},
+		{method: http.MethodGet, path: "/search?q=to&offset=9223372036854775807", status: http.StatusBadRequest, code: codeInvalidParam, param: "offset"},
+	} {
+		req, err := http.NewRequest(td.method, server.URL+td.path, nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		var got errorResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, td.status, res.StatusCode, td.path)
+		require.Equal(t, "application/json", res.Header.Get("Content-Type"), td.path)
+		require.Equal(t, td.code, got.Error.Code, td.path)
+		require.Equal(t, td.param, got.Error.Param, td.path)
+	}
+}
+
+func TestServer_search(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got searchResult
+	status := getJSON(t, server.URL+"/autocomplete?q=kev&type=cast", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 1, got.Total)
+	require.Equal(t, "Kevin Bacon", got.Results[0].Name)
+
+	res, err := http.Get(server.URL + "/search?q=kev&limit=1000")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestServer_allLinks(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got allLinksResult
+	status := getJSON(t, server.URL+"/links/all?a=Kevin+Bacon&b=Tom+Cruise", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, uint64(1), got.Count)
+	require.Equal(t, [][]linksResult{{
+		{Name: "Kevin Bacon", Type: "cast"},
+		{Name: "A Few Good Men", Type: "movie"},
+		{Name: "Tom Cruise", Type: "cast"},
+	}}, got.Paths)
+}
+
+func TestServer_link_weighted(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got linkResult
+	status := getJSON(t, server.URL+"/link?a=Elizabeth+Perkins&b=Kevin+Bacon&mode=weighted", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, got.Path, 5)
+	require.NotNil(t, got.Cost)
+	require.InDelta(t, 1+math.Log2(3), *got.Cost, 1e-9)
+
+	status = getJSON(t, server.URL+"/link?a=Elizabeth+Perkins&b=Kevin+Bacon&mode=weighted&cost=recency", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, got.Path, 5)
+	// Big (1988) and Apollo 13 (1995) against Vanilla Sky (2001)
+	require.InDelta(t, 2.3+1.6, *got.Cost, 1e-9)
+}
+
+func TestServer_centerCache(t *testing.T) {
+	s := NewServer(newFixtureBaconator(t), WithCenterCacheSize(1))
+	require.NoError(t, s.PrecomputeCenters("Kevin Bacon"))
+	require.Error(t, s.PrecomputeCenters("Nobody At All"))
+	require.Error(t, s.PrecomputeCenters("Tom Cruz"))
+	server := httptest.NewServer(s)
+	for _, p := range []string{"Kevin+Bacon", "kevin+bacon", "Tom+Hanks", "Tom+Hanks", "Tom+Cruise", "Tom+Hanks"} {
+		var got struct {
+			Total int `json:"total_linkable"`
+		}
+		status := getJSON(t, server.URL+"/center?p="+p, &got)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 13, got.Total)
+	}
+	var got statsResult
+	status := getJSON(t, server.URL+"/stats", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, centerCacheStats{
+		Hits:      3,
+		Misses:    3,
+		Evictions: 2,
+		Size:      1,
+		Capacity:  1,
+		Pinned:    1,
+	}, got.CenterCache)
+}
+
+func TestServer_topCenters(t *testing.T) {
+	b := newFixtureBaconator(t)
+	ranking := b.RankCenters(0)
+	server := httptest.NewServer(NewServer(b, WithCenterRanking(ranking)))
+	var got topCentersResult
+	status := getJSON(t, server.URL+"/centers/top?n=3", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 13, got.ComponentSize)
+	require.Equal(t, ranking.Centers[:3], got.Centers)
+
+	status = getJSON(t, server.URL+"/centers/top?n=100", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, got.Centers, 13)
+
+	var errRes errorResponse
+	status = getJSON(t, server.URL+"/centers/top?n=0", &errRes)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "n", errRes.Error.Param)
+}
+
+func TestServer_component(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got componentResult
+	status := getJSON(t, server.URL+"/component?p=kevin+bacon", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, componentResult{
+		Resolved:   &nameMatch{Query: "kevin bacon", Name: "Kevin Bacon", Method: matchNormalized},
+		Component:  0,
+		CastCount:  13,
+		MovieCount: 7,
+		Largest:    true,
+	}, got)
+
+	got = componentResult{}
+	status = getJSON(t, server.URL+"/component?p=Other+Loner", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 1, got.Component)
+	require.Equal(t, 2, got.CastCount)
+	require.Equal(t, 1, got.MovieCount)
+	require.False(t, got.Largest)
+}
+
+func TestServer_farthest(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got farthestResult
+	status := getJSON(t, server.URL+"/farthest?p=Kevin+Bacon", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Kevin Bacon", got.Resolved.Name)
+	require.Equal(t, 3, got.BaconNumber)
+	require.Equal(t, []string{"Harrison Ford"}, got.Cast)
+}
+
+func TestServer_related(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got relatedResult
+	status := getJSON(t, server.URL+"/related?p=Kevin+Bacon&limit=2", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Kevin Bacon", got.Resolved.Name)
+	require.Len(t, got.Related, 2)
+	require.Equal(t, "Tom Hanks", got.Related[0].Name)
+
+	// less damping keeps the walk closer to Kevin Bacon
+	server = httptest.NewServer(NewServer(newFixtureBaconator(t), WithRelatedPageRank(0.5, 1e-3)))
+	var coarse relatedResult
+	status = getJSON(t, server.URL+"/related?p=Kevin+Bacon&limit=2", &coarse)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, coarse.Related, 2)
+	require.NotEqual(t, got.Related[0].Score, coarse.Related[0].Score)
+}
+
+func TestServer_costars(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got costarsResult
+	status := getJSON(t, server.URL+"/costars?p=Sölo+Äctor", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Sölo Äctor", got.Resolved.Name)
+	require.Equal(t, 1, got.Total)
+	require.Equal(t, []*costar{{
+		Name:           "Other Loner",
+		Collaborations: 1,
+		Movies:         []*costarMovie{{Title: "Lonely Film", Year: 1950}},
+	}}, got.Costars)
+}
+
+func TestServer_actor(t *testing.T) {
+	b := newFixtureBaconator(t)
+	server := httptest.NewServer(NewServer(b))
+	var got actorResult
+	status := getJSON(t, server.URL+"/actor?p=harrison+ford", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Harrison Ford", got.Resolved.Name)
+	require.Equal(t, "Kevin Bacon", got.Center)
+	require.Equal(t, 3, *got.BaconNumber)
+	require.Equal(t, []*filmographyEntry{{Title: "Witness (1985 film)", Year: 1985, CastSize: 2}}, got.Filmography)
+
+	server = httptest.NewServer(NewServer(b, WithDefaultCenter("Tom Cruise")))
+	got = actorResult{}
+	status = getJSON(t, server.URL+"/actor?p=harrison+ford", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Tom Cruise", got.Center)
+	require.Equal(t, 2, *got.BaconNumber)
+
+	for _, name := range []string{"Nobody Here At All", "Tom Cruz"} {
+		server = httptest.NewServer(NewServer(b, WithDefaultCenter(name)))
+		got = actorResult{}
+		status = getJSON(t, server.URL+"/actor?p=harrison+ford", &got)
+		require.Equal(t, http.StatusOK, status)
+		require.Empty(t, got.Center, name)
+		require.Nil(t, got.BaconNumber, name)
+	}
+}
+
+func TestServer_movie(t *testing.T) {
+	server := httptest.NewServer(NewServer(newFixtureBaconator(t)))
+	var got movieResult
+	status := getJSON(t, server.URL+"/movie?t=big", &got)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Big (film)", got.Resolved.Name)
+	require.Equal(t, 1988, got.Year)
+	require.Equal(t, []*movieCastMember{
+		{Name: "Elizabeth Perkins", Degree: 1},
+		{Name: "Tom Hanks", Degree: 2},
+	}, got.Cast)
+	require.True(t, got.Bridge)
+	require.Equal(t, 1, got.StrandedCast)
+}
+
+func Test_centerCache(t *testing.T) {
+	cache := newCenterCache(2)
+	a, b, c := &centerResult{Total: 1}, &centerResult{Total: 2}, &centerResult{Total: 3}
+	computed := 0
+	result := func(res *centerResult) func() *centerResult {
+		return func() *centerResult {
+			computed++
+			return res
+		}
+	}
+	require.Same(t, a, cache.getOrCompute(1, result(a)))
+	require.Same(t, b, cache.getOrCompute(2, result(b)))
+	require.Same(t, a, cache.getOrCompute(1, result(c)))
+	require.Same(t, c, cache.getOrCompute(3, result(c)))
+	require.Equal(t, 3, computed)
+	// 2 was evicted
+	require.Same(t, b, cache.getOrCompute(2, result(b)))
+	require.Equal(t, 4, computed)
+
+	cache.pinComputed(1, result(a))
+	require.Same(t, b, cache.getOrCompute(4, result(b)))
+	require.Same(t, a, cache.getOrCompute(1, result(c)))
+	require.Equal(t, 6, computed)
+	stats := cache.statsSnapshot()
+	require.Equal(t, 2, stats.Size)
+	require.Equal(t, 1, stats.Pinned)
+	require.Equal(t, uint64(3), stats.Evictions)
+	require.Equal(t, uint64(2), stats.Hits)
+	require.Equal(t, uint64(5), stats.Misses)
+
+	disabled := newCenterCache(0)
+	disabled.getOrCompute(1, result(a))
+	disabled.getOrCompute(1, result(a))
+	require.Equal(t, 8, computed)
+	require.Equal(t, 0, disabled.statsSnapshot().Size)
+}
+
+func Test_centerCache_shared(t *testing.T) {
+	cache := newCenterCache(2)
+	want := &centerResult{Total: 1}
+	release := make(chan struct{})
+	var calls int32
+	compute := func() *centerResult {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return want
+	}
+	const callers = 4
+	results := make(chan *centerResult, callers+1)
+	for i := 0; i < callers; i++ {
+		go func() {
+			results <- cache.getOrCompute(1, compute)
+		}()
+	}
+	go func() {
+		cache.pinComputed(1, compute)
+		results <- want
+	}()
+	require.Eventually(t, func() bool {
+		return cache.statsSnapshot().Shared == callers
+	}, time.Second, time.Millisecond)
+	close(release)
+	for i := 0; i < callers+1; i++ {
+		require.Same(t, want, <-results)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	stats := cache.statsSnapshot()
+	require.Equal(t, uint64(callers), stats.Misses)
+	require.Equal(t, 1, stats.Pinned)
+	require.Same(t, want, cache.getOrCompute(1, compute))
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func Test_centerCache_panic(t *testing.T) {
+	cache := newCenterCache(2)
+	release := make(chan struct{})
+	compute := func() *centerResult {
+		<-release
+		panic("boom")
+	}
+	const callers = 3
+	recovered := make(chan interface{}, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer func() {
+				recovered <- recover()
+			}()
+			cache.getOrCompute(1, compute)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return cache.statsSnapshot().Shared == callers-1
+	}, time.Second, time.Millisecond)
+	close(release)
+	for i := 0; i < callers; i++ {
+		require.Equal(t, "boom", <-recovered)
+	}
+	want := &centerResult{Total: 1}
+	require.Same(t, want, cache.getOrCompute(1, func() *centerResult {
+		return want
+	}))
+	require.Equal(t, 0, len(cache.calls))
+}
+
+func getJSON(t *testing.T, u string, v interface{}) int {
+	t.Helper()
+	res, err := http.Get(u)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, res.Body.Close())
+	}()
+	require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+	return res.StatusCode
+}
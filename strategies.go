@@ -0,0 +1,154 @@
+package baconator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/willabides/baconator/internal/graph"
+)
+
+// defaultStrategy is used when a link request doesn't name a strategy
+const defaultStrategy = "oldest"
+
+// pathStrategy decides which of several equally short links is preferred
+type pathStrategy struct {
+	name        string
+	description string
+
+	// priority returns the graph.PriorityFunc for b. It is only called when b is loaded to build
+	// the strategy's graph.NeighborOrder.
+	priority func(b *Baconator) graph.PriorityFunc
+}
+
+// pathStrategies is the registry of strategies by name
+var pathStrategies = map[string]*pathStrategy{}
+
+func registerPathStrategy(strategy *pathStrategy) {
+	if _, ok := pathStrategies[strategy.name]; ok {
+		panic(fmt.Sprintf("path strategy %q is already registered", strategy.name))
+	}
+	pathStrategies[strategy.name] = strategy
+}
+
+// pathStrategyNames returns the sorted names of all registered strategies
+func pathStrategyNames() []string {
+	names := make([]string, 0, len(pathStrategies))
+	for name := range pathStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type unknownStrategyError struct {
+	name string
+}
+
+func (e *unknownStrategyError) Error() string {
+	return fmt.Sprintf("unknown strategy %q. valid strategies are: %s", e.name, strings.Join(pathStrategyNames(), ", "))
+}
+
+func init() {
+	registerPathStrategy(&pathStrategy{
+		name:        "oldest",
+		description: "prefer the oldest films",
+		priority: func(b *Baconator) graph.PriorityFunc {
+			return b.moviePriority(func(node graph.Node) int64 {
+				year := int64(b.years[node])
+				if year == 0 {
+					year = 10000
+				}
+				return -year
+			})
+		},
+	})
+	registerPathStrategy(&pathStrategy{
+		name:        "newest",
+		description: "prefer the newest films",
+		priority: func(b *Baconator) graph.PriorityFunc {
+			return b.moviePriority(func(node graph.Node) int64 {
+				return int64(b.years[node])
+			})
+		},
+	})
+	registerPathStrategy(&pathStrategy{
+		name:        "popular",
+		description: "prefer films with the largest cast",
+		priority: func(b *Baconator) graph.PriorityFunc {
+			return b.moviePriority(func(node graph.Node) int64 {
+				return int64(b.degree(node))
+			})
+		},
+	})
+	registerPathStrategy(&pathStrategy{
+		name:        "famous_costar",
+		description: "prefer co-stars who have been in the most films",
+		priority: func(b *Baconator) graph.PriorityFunc {
+			return func(node graph.Node) int64 {
+				if b.NodeInfo[node].Type != castNode {
+					return 0
+				}
+				return int64(b.degree(node))
+			}
+		},
+	})
+	registerPathStrategy(&pathStrategy{
+		name:        "alphabetical",
+		description: "prefer films and co-stars whose names come first alphabetically",
+		priority: func(b *Baconator) graph.PriorityFunc {
+			ranks := b.alphabeticalRanks()
+			return func(node graph.Node) int64 {
+				return -int64(ranks[node])
+			}
+		},
+	})
+}
+
+// moviePriority wraps fn so that it only applies to movie nodes
+func (b *Baconator) moviePriority(fn graph.PriorityFunc) graph.PriorityFunc {
+	return func(node graph.Node) int64 {
+		if b.NodeInfo[node].Type != movieNode {
+			return 0
+		}
+		return fn(node)
+	}
+}
+
+// alphabeticalRanks returns each node's position when all nodes are sorted by name
+func (b *Baconator) alphabeticalRanks() []int {
+	nodes := make([]graph.Node, len(b.NodeInfo))
+	for i := range nodes {
+		nodes[i] = graph.Node(i)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return b.NodeInfo[nodes[i]].Name < b.NodeInfo[nodes[j]].Name
+	})
+	ranks := make([]int, len(nodes))
+	for rank, node := range nodes {
+		ranks[node] = rank
+	}
+	return ranks
+}
+
+// buildNeighborOrders precomputes the neighbor order of every registered strategy
+func (b *Baconator) buildNeighborOrders() map[string]*graph.NeighborOrder {
+	orders := make(map[string]*graph.NeighborOrder, len(pathStrategies))
+	for name, strategy := range pathStrategies {
+		orders[name] = b.Graph.OrderNeighbors(strategy.priority(b))
+	}
+	return orders
+}
+
+// neighborOrder returns the neighbor order for the named strategy. An empty name is the default strategy.
+func (b *Baconator) neighborOrder(name string) (*graph.NeighborOrder, error) {
+	b.loadIndexes()
+	if name == "" {
+		name = defaultStrategy
+	}
+	order, ok := b.neighborOrders[name]
+	if !ok {
+		return nil, &unknownStrategyError{name: name}
+	}
+	return order, nil
+}